@@ -1,8 +1,14 @@
 package app
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
+	"os/signal"
+	"syscall"
+	"time"
 
 	_ "github.com/Komilov31/comment-tree/docs"
 
@@ -34,17 +40,113 @@ func Run() error {
 	}
 
 	repository := repository.New(db)
-	service := service.New(repository)
+	service := service.New(
+		repository,
+		service.DeleteMode(config.Cfg.Comments.DeleteMode),
+		service.Languages{
+			Configs: config.Cfg.Search.Languages,
+			Default: config.Cfg.Search.DefaultLanguage,
+		},
+	)
+	expectedMigration, err := latestMigration(migrationsDir)
+	if err != nil {
+		log.Fatal("could not find expected migration version: " + err.Error())
+	}
+
+	if config.Cfg.Auth.ProxySecret == "" {
+		zlog.Logger.Warn().Msg("AUTH_PROXY_SECRET is not set, identity headers are ignored and only reads are allowed")
+	}
+	trustedProxy := handler.TrustedProxy(config.Cfg.Auth.ProxySecret)
+	requestTimeout := handler.RequestTimeout(time.Duration(config.Cfg.HttpServer.Timeout) * time.Second)
+	health := handler.NewHealth(service, expectedMigration, buildInfo())
 	handler := handler.New(service)
 
+	// the first SIGINT or SIGTERM starts a graceful shutdown, a second one
+	// kills the process right away
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	purgerDone := make(chan struct{})
+	go func() {
+		defer close(purgerDone)
+		service.RunTrashPurger(
+			ctx,
+			time.Duration(config.Cfg.Trash.PurgeIntervalMinutes)*time.Minute,
+			time.Duration(config.Cfg.Trash.RetentionHours)*time.Hour,
+		)
+	}()
+
 	router := ginext.New()
-	registerRoutes(router, handler)
+	router.Use(trustedProxy, requestTimeout)
+	registerRoutes(router, handler, health)
 
-	zlog.Logger.Info().Msg("succesfully started server on " + config.Cfg.HttpServer.Address)
-	return router.Run(config.Cfg.HttpServer.Address)
+	server := newServer(config.Cfg.HttpServer, router)
+	serverErr := make(chan error, 1)
+	go func() {
+		zlog.Logger.Info().Msg("succesfully started server on " + config.Cfg.HttpServer.Address)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+	}()
+
+	var runErr error
+	select {
+	case <-ctx.Done():
+		zlog.Logger.Info().Msg("shutting down server")
+	case err := <-serverErr:
+		runErr = fmt.Errorf("could not serve http: %w", err)
+	}
+	stop()
+
+	shutdownCtx, cancel := context.WithTimeout(
+		context.Background(),
+		time.Duration(config.Cfg.HttpServer.ShutdownTimeout)*time.Second,
+	)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		runErr = errors.Join(runErr, fmt.Errorf("could not drain in-flight requests: %w", err))
+	}
+	<-purgerDone
+
+	if err := closeDB(db); err != nil {
+		runErr = errors.Join(runErr, err)
+	}
+
+	zlog.Logger.Info().Msg("server stopped")
+	return runErr
+}
+
+// writeTimeoutMargin leaves a handler time to answer after the deadline of
+// its request has cancelled its queries.
+const writeTimeoutMargin = time.Second
+
+func newServer(cfg config.HttpServerConfig, handler http.Handler) *http.Server {
+	timeout := time.Duration(cfg.Timeout) * time.Second
+	return &http.Server{
+		Addr:              cfg.Address,
+		Handler:           handler,
+		ReadHeaderTimeout: timeout,
+		ReadTimeout:       timeout,
+		WriteTimeout:      timeout + writeTimeoutMargin,
+		IdleTimeout:       time.Duration(cfg.IdleTimeout) * time.Second,
+	}
+}
+
+func closeDB(db *dbpg.DB) error {
+	var errs []error
+	if err := db.Master.Close(); err != nil {
+		errs = append(errs, fmt.Errorf("could not close db: %w", err))
+	}
+	for _, slave := range db.Slaves {
+		if err := slave.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("could not close db replica: %w", err))
+		}
+	}
+	return errors.Join(errs...)
 }
 
-func registerRoutes(engine *ginext.Engine, handler *handler.Handler) {
+func registerRoutes(engine *ginext.Engine, handler *handler.Handler, health *handler.Health) {
 	// Register static files
 	engine.LoadHTMLFiles("/app/static/index.html")
 	engine.Static("/static", "/app/static")
@@ -52,14 +154,32 @@ func registerRoutes(engine *ginext.Engine, handler *handler.Handler) {
 	// POST requests
 	engine.POST("/comments", handler.CreateComment)
 	engine.POST("/comments/search", handler.GetCommentsByTextSearch)
+	engine.POST("/comments/:id/restore", handler.RestoreCommentById)
+	engine.POST("/comments/:id/move", handler.MoveComment)
+	engine.POST("/comments/:id/vote", handler.VoteComment)
+	engine.POST("/comments/:id/reactions", handler.AddReaction)
 
 	// GET requests
 	engine.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	engine.GET("/healthz", health.Healthz)
+	engine.GET("/readyz", health.Readyz)
+	engine.GET("/version", health.Version)
 	engine.GET("/", handler.GetMainPage)
 	engine.GET("/comments", handler.GetComments)
 	engine.GET("/comments/all", handler.GetAllComments)
+	engine.GET("/comments/suggest", handler.SuggestComments)
+	engine.GET("/comments/:id", handler.GetCommentById)
+	engine.GET("/comments/:id/ancestors", handler.GetCommentAncestors)
+	engine.GET("/comments/:id/context", handler.GetCommentContext)
+	engine.GET("/comments/:id/revisions", handler.GetCommentRevisions)
+	engine.GET("/threads/:key/comments", handler.GetThreadComments)
+
+	// PATCH requests
+	engine.PATCH("/comments/:id", handler.UpdateComment)
 
 	// DELETE request
 	engine.DELETE("/comments/:id", handler.DeleteCommentById)
+	engine.DELETE("/comments/:id/reactions/:emoji", handler.RemoveReaction)
+	engine.DELETE("/admin/comments/:id", handler.HardDeleteCommentById)
 
 }
@@ -0,0 +1,69 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+
+	"github.com/Komilov31/comment-tree/internal/model"
+)
+
+// Version is the release of the binary, set at build time with
+// -ldflags "-X github.com/Komilov31/comment-tree/cmd/app.Version=...".
+var Version = "dev"
+
+// migrationsDir holds the goose migrations shipped with the service. The
+// database is ready once the newest of them has been applied.
+const migrationsDir = "/app/migrations"
+
+func buildInfo() model.BuildInfo {
+	info := model.BuildInfo{
+		Version:   Version,
+		GoVersion: runtime.Version(),
+	}
+
+	if build, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range build.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				info.Commit = setting.Value
+			case "vcs.time":
+				info.CommitTime = setting.Value
+			}
+		}
+	}
+
+	return info
+}
+
+// latestMigration returns the version of the newest migration in dir, the
+// number its file name starts with.
+func latestMigration(dir string) (int64, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, fmt.Errorf("could not read migrations: %w", err)
+	}
+
+	var latest int64
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".sql" {
+			continue
+		}
+
+		prefix, _, _ := strings.Cut(entry.Name(), "_")
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil {
+			continue
+		}
+		latest = max(latest, version)
+	}
+
+	if latest == 0 {
+		return 0, fmt.Errorf("could not find migrations in %s", dir)
+	}
+	return latest, nil
+}
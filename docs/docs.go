@@ -44,9 +44,94 @@ const docTemplate = `{
                 }
             }
         },
+        "/admin/comments/{id}": {
+            "delete": {
+                "description": "Безвозвратно удаляет комментарий и все вложенные ответы независимо от comments.delete_mode. Доступно только администраторам",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Удалить комментарий вместе с ответами (администратор)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID комментария для удаления",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID пользователя",
+                        "name": "X-User-Id",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Роль пользователя (admin)",
+                        "name": "X-User-Role",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "status\":\"successfully deleted comment",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "error\":\"invalid id was provided\" or \"could not delete comment from db",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error\":\"authentication required",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "error\":\"admin role required",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error\":\"there is not comment with such id",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
         "/comments": {
             "get": {
-                "description": "Получает комментарии с пагинацией и по ID родительского комментария",
+                "description": "Получает комментарии с пагинацией и по ID родительского комментария.\nЕсли передан page, ответ содержит страницу, общее количество комментариев (total_count) и ссылки next/prev.\nЕсли передан limit без page или курсор after/before, используется пагинация по курсору (next_cursor/prev_cursor).\nБез page и limit возвращается массив с деревом комментария parent.\nmax_depth и max_children ограничивают глубину дерева и количество загружаемых ответов каждого комментария.\nУ комментариев с незагруженными ответами есть поле more со ссылкой next на следующую порцию ответов (after в этом режиме — курсор среди прямых ответов parent).",
                 "consumes": [
                     "application/json"
                 ],
@@ -58,6 +143,12 @@ const docTemplate = `{
                 ],
                 "summary": "Получить комментарии",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Ключ треда (по умолчанию default)",
+                        "name": "thread",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
                         "description": "ID родительского комментария",
@@ -75,15 +166,55 @@ const docTemplate = `{
                         "description": "Количество комментариев на странице",
                         "name": "limit",
                         "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "oldest",
+                            "newest",
+                            "most-replied",
+                            "top-voted",
+                            "best"
+                        ],
+                        "type": "string",
+                        "description": "Порядок комментариев на каждом уровне дерева (по умолчанию oldest)",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Курсор: вернуть комментарии после него (next_cursor предыдущей страницы)",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Курсор: вернуть комментарии перед ним (prev_cursor предыдущей страницы)",
+                        "name": "before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Максимальная глубина дерева относительно parent (0 — без ограничения)",
+                        "name": "max_depth",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Максимальное количество ответов каждого комментария (0 — без ограничения), совместимо только с sort oldest и newest",
+                        "name": "max_children",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Список комментариев",
+                        "description": "Страница комментариев",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/github_com_Komilov31_comment-tree_internal_model.Comment"
+                            "$ref": "#/definitions/github_com_Komilov31_comment-tree_internal_model.CommentsPage"
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "Ссылки на соседние страницы (rel=next, rel=prev)"
                             }
                         }
                     },
@@ -108,7 +239,7 @@ const docTemplate = `{
                 }
             },
             "post": {
-                "description": "Создает новый комментарий в системе",
+                "description": "Создает новый комментарий в системе. Язык комментария (language) определяет, как его текст индексируется для поиска; если он не указан, язык определяется по тексту",
                 "consumes": [
                     "application/json"
                 ],
@@ -120,6 +251,25 @@ const docTemplate = `{
                 ],
                 "summary": "Создать комментарий",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ID автора",
+                        "name": "X-User-Id",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Отображаемое имя автора",
+                        "name": "X-User-Name",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "URL аватара автора",
+                        "name": "X-User-Avatar",
+                        "in": "header"
+                    },
                     {
                         "description": "Данные для создания комментария",
                         "name": "comment",
@@ -138,7 +288,16 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "error\":\"invalid payload",
+                        "description": "error\":\"invalid payload\" or \"unsupported language",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error\":\"authentication required",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -160,7 +319,7 @@ const docTemplate = `{
         },
         "/comments/all": {
             "get": {
-                "description": "Возвращает полный список всех комментариев без фильтров c пагинацией",
+                "description": "Возвращает полный список всех комментариев без фильтров c пагинацией. Без page и limit возвращаются все комментарии треда",
                 "consumes": [
                     "application/json"
                 ],
@@ -171,13 +330,49 @@ const docTemplate = `{
                     "comments"
                 ],
                 "summary": "Получить все комментарии",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Ключ треда (по умолчанию default)",
+                        "name": "thread",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Номер страницы для пагинации",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Количество комментариев на странице",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "oldest",
+                            "newest",
+                            "most-replied",
+                            "top-voted",
+                            "best"
+                        ],
+                        "type": "string",
+                        "description": "Порядок комментариев на каждом уровне дерева (по умолчанию oldest)",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "Все комментарии",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/github_com_Komilov31_comment-tree_internal_model.Comment"
+                            "$ref": "#/definitions/github_com_Komilov31_comment-tree_internal_model.CommentsPage"
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "Ссылки на соседние страницы (rel=next, rel=prev)"
                             }
                         }
                     },
@@ -195,7 +390,7 @@ const docTemplate = `{
         },
         "/comments/search": {
             "post": {
-                "description": "Ищет комментарии, содержащие указанный текст",
+                "description": "Ищет комментарии, содержащие указанный текст. Без page и limit возвращаются все найденные комментарии\nТекст поддерживает синтаксис веб-поиска: \"фраза в кавычках\", or между альтернативами и -слово для исключения. Фильтры from и to ограничивают дату создания, author_id — автора, min_score — минимальный рейтинг, roots_only оставляет только корневые комментарии\nmode выбирает способ поиска: fulltext (по умолчанию) — по словам, fuzzy — по сходству триграмм, находящему части слов, имена пользователей и опечатки, both — оба способа с суммой релевантностей. similarity задает порог сходства для fuzzy и both (от 0 до 1, по умолчанию 0.6)\nНайденные комментарии не вкладываются друг в друга: у каждого есть root_id — ID корневого комментария треда и ancestors — цепочка предков от корня до родителя\nУ каждого найденного комментария есть релевантность rank. Если передан highlight, у комментариев есть фрагмент snippet, в котором найденные слова обрамлены тегами start_sel и stop_sel (по умолчанию \u003cb\u003e и \u003c/b\u003e), а max_fragments задает количество отдельных фрагментов (0 — один фрагмент, не больше 10)",
                 "consumes": [
                     "application/json"
                 ],
@@ -215,20 +410,54 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/github_com_Komilov31_comment-tree_internal_dto.SearchText"
                         }
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Номер страницы для пагинации",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Количество комментариев на странице",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "oldest",
+                            "newest",
+                            "most-replied",
+                            "top-voted",
+                            "best"
+                        ],
+                        "type": "string",
+                        "description": "Порядок комментариев на каждом уровне дерева (по умолчанию по релевантности)",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Язык комментариев для поиска (по умолчанию поиск по всем языкам)",
+                        "name": "lang",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "Найденные комментарии",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/github_com_Komilov31_comment-tree_internal_model.Comment"
+                            "$ref": "#/definitions/github_com_Komilov31_comment-tree_internal_model.CommentsPage"
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "Ссылки на соседние страницы (rel=next, rel=prev)"
                             }
                         }
                     },
                     "400": {
-                        "description": "error\":\"invalid payload",
+                        "description": "error\":\"invalid payload\", \"invalid highlight\", \"invalid search filter\" or \"unsupported language",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -248,9 +477,131 @@ const docTemplate = `{
                 }
             }
         },
+        "/comments/suggest": {
+            "get": {
+                "description": "Возвращает подсказки для поисковой строки по мере ввода: фрагменты лучших подходящих комментариев и частые слова, начинающиеся с последнего введенного слова. Каждое слово запроса ищется как префикс. Ответы кешируются на сервере на 30 секунд",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "comments"
+                ],
+                "summary": "Подсказки для поиска",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Введенный текст",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Ключ треда (по умолчанию default)",
+                        "name": "thread",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Количество комментариев и слов (от 1 до 10, по умолчанию 5)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Язык комментариев для поиска (по умолчанию поиск по всем языкам)",
+                        "name": "lang",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Подсказки",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_Komilov31_comment-tree_internal_model.Suggestions"
+                        }
+                    },
+                    "400": {
+                        "description": "error\":\"invalid limit was provided\" or \"unsupported language",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error\":\"could not get suggestions",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
         "/comments/{id}": {
+            "get": {
+                "description": "Возвращает один комментарий без ответов",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "comments"
+                ],
+                "summary": "Получить комментарий",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID комментария",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Комментарий",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_Komilov31_comment-tree_internal_model.Comment"
+                        }
+                    },
+                    "400": {
+                        "description": "error\":\"invalid id was provided",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error\":\"there is not comment with such id",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error\":\"could not get comment",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
             "delete": {
-                "description": "Удаляет комментарий по его уникальному идентификатору",
+                "description": "Удаляет комментарий по его уникальному идентификатору. В зависимости от настройки comments.delete_mode комментарий либо превращается в \"[deleted]\" с сохранением ответов, либо удаляется вместе со всеми ответами. Вместе с ответами комментарий удаляет только администратор, у остальных пользователей он всегда превращается в \"[deleted]\"",
                 "consumes": [
                     "application/json"
                 ],
@@ -268,6 +619,13 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID автора",
+                        "name": "X-User-Id",
+                        "in": "header",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -288,30 +646,1033 @@ const docTemplate = `{
                                 "type": "string"
                             }
                         }
-                    }
-                }
-            }
-        }
-    },
-    "definitions": {
-        "github_com_Komilov31_comment-tree_internal_dto.CreateComment": {
-            "type": "object",
-            "properties": {
-                "created_at": {
+                    },
+                    "401": {
+                        "description": "error\":\"authentication required",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "error\":\"comment belongs to another author",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error\":\"there is not comment with such id",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Изменяет текст комментария. Предыдущая версия сохраняется в истории правок",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "comments"
+                ],
+                "summary": "Редактировать комментарий",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID комментария",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID автора",
+                        "name": "X-User-Id",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Новый текст комментария",
+                        "name": "comment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_Komilov31_comment-tree_internal_dto.UpdateComment"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Обновленный комментарий",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_Komilov31_comment-tree_internal_model.Comment"
+                        }
+                    },
+                    "400": {
+                        "description": "error\":\"invalid payload\" or \"invalid id was provided",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error\":\"authentication required",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "error\":\"comment belongs to another author",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error\":\"there is not comment with such id",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error\":\"could not update comment",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/comments/{id}/ancestors": {
+            "get": {
+                "description": "Возвращает цепочку комментариев от корня треда до родителя указанного комментария. Для корневого комментария возвращается пустой массив",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "comments"
+                ],
+                "summary": "Получить предков комментария",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID комментария",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Предки комментария, начиная с корня",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_Komilov31_comment-tree_internal_model.Comment"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "error\":\"invalid id was provided",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error\":\"there is not comment with such id",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error\":\"could not get ancestors",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/comments/{id}/context": {
+            "get": {
+                "description": "Возвращает дерево треда, сокращенное до окрестности комментария: цепочку от корня треда до комментария, все ответы его up ближайших предков (соседние комментарии на up уровнях) и down уровней его ответов.\nФормат ответа такой же, как у дерева комментариев.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "comments"
+                ],
+                "summary": "Получить комментарий в контексте",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID комментария",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Количество уровней с соседними комментариями (по умолчанию 1)",
+                        "name": "up",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Количество уровней ответов на комментарий (по умолчанию 3)",
+                        "name": "down",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "oldest",
+                            "newest",
+                            "most-replied",
+                            "top-voted",
+                            "best"
+                        ],
+                        "type": "string",
+                        "description": "Порядок комментариев на каждом уровне дерева (по умолчанию oldest)",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Дерево вокруг комментария",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_Komilov31_comment-tree_internal_model.Comment"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "error\":\"invalid id was provided",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error\":\"there is not comment with such id",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error\":\"could not get comment context",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/comments/{id}/move": {
+            "post": {
+                "description": "Переносит комментарий вместе со всеми ответами под другой комментарий или делает его корневым (parent_id: null). Нельзя перенести комментарий под самого себя или под один из его ответов. Ответы всегда находятся в треде родителя, поэтому при переносе в другой тред поддерево переходит вместе с ним. Доступно только администраторам",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Перенести комментарий (администратор)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID переносимого комментария",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID пользователя",
+                        "name": "X-User-Id",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Роль пользователя (admin)",
+                        "name": "X-User-Role",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Новый родительский комментарий",
+                        "name": "move",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_Komilov31_comment-tree_internal_dto.MoveComment"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Перенесенное поддерево",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_Komilov31_comment-tree_internal_model.Comment"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "error\":\"invalid payload\", \"there is not parent comment with provided id\" or \"comment cannot be moved under itself or its replies",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error\":\"authentication required",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "error\":\"admin role required",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error\":\"there is not comment with such id",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error\":\"could not move comment",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/comments/{id}/reactions": {
+            "post": {
+                "description": "Добавляет реакцию-эмодзи текущего пользователя к комментарию. Повторная реакция тем же эмодзи ничего не меняет",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reactions"
+                ],
+                "summary": "Добавить реакцию",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID комментария",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID пользователя",
+                        "name": "X-User-Id",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Эмодзи реакции",
+                        "name": "reaction",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_Komilov31_comment-tree_internal_dto.Reaction"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Реакции комментария",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_Komilov31_comment-tree_internal_model.Reaction"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "error\":\"invalid payload\" or \"invalid reaction",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error\":\"authentication required",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error\":\"there is not comment with such id",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error\":\"could not change reaction",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/comments/{id}/reactions/{emoji}": {
+            "delete": {
+                "description": "Убирает реакцию-эмодзи текущего пользователя с комментария",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reactions"
+                ],
+                "summary": "Убрать реакцию",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID комментария",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Эмодзи реакции (URL-encoded)",
+                        "name": "emoji",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID пользователя",
+                        "name": "X-User-Id",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Оставшиеся реакции комментария",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_Komilov31_comment-tree_internal_model.Reaction"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "error\":\"invalid id was provided\" or \"invalid reaction",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error\":\"authentication required",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error\":\"there is not comment with such id",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error\":\"could not change reaction",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/comments/{id}/restore": {
+            "post": {
+                "description": "Отменяет удаление комментария: мягко удаленный комментарий снова становится видимым, а удаленный вместе с ответами возвращается из корзины со всеми этими ответами. ID и даты создания сохраняются. Восстановить может автор комментария или администратор",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "comments"
+                ],
+                "summary": "Восстановить удаленный комментарий",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID удаленного комментария",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID автора",
+                        "name": "X-User-Id",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "status\":\"successfully restored comment",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "error\":\"invalid id was provided\" or \"there is not parent comment with provided id",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error\":\"authentication required",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "error\":\"comment belongs to another author",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error\":\"there is not deleted comment with such id",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error\":\"could not restore comment",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/comments/{id}/revisions": {
+            "get": {
+                "description": "Возвращает все версии комментария от первой до текущей вместе с отличиями от предыдущей версии",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "comments"
+                ],
+                "summary": "История правок комментария",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID комментария",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Версии комментария",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_Komilov31_comment-tree_internal_model.Revision"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "error\":\"invalid id was provided",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error\":\"there is not comment with such id",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error\":\"could not get revisions",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/comments/{id}/vote": {
+            "post": {
+                "description": "Ставит голос \"за\" (up) или \"против\" (down) либо отменяет голос (clear). У пользователя может быть только один голос за комментарий",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "votes"
+                ],
+                "summary": "Проголосовать за комментарий",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID комментария",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID пользователя",
+                        "name": "X-User-Id",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Голос: up, down или clear",
+                        "name": "vote",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_Komilov31_comment-tree_internal_dto.Vote"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Итог голосования по комментарию",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_Komilov31_comment-tree_internal_model.Votes"
+                        }
+                    },
+                    "400": {
+                        "description": "error\":\"invalid payload\" or \"invalid vote",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "error\":\"authentication required",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "error\":\"there is not comment with such id",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "error\":\"could not vote",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/healthz": {
+            "get": {
+                "description": "Отвечает, пока процесс жив, не обращаясь к базе данных",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Проверка жизнеспособности",
+                "responses": {
+                    "200": {
+                        "description": "status\":\"ok",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "description": "Проверяет, что база данных доступна и к ней применены все миграции, поставляемые с сервисом. Пока сервис не готов, на него не стоит направлять запросы",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Проверка готовности",
+                "responses": {
+                    "200": {
+                        "description": "Сервис готов",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_Komilov31_comment-tree_internal_model.Readiness"
+                        }
+                    },
+                    "503": {
+                        "description": "База данных недоступна или миграции не применены",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_Komilov31_comment-tree_internal_model.Readiness"
+                        }
+                    }
+                }
+            }
+        },
+        "/threads/{key}/comments": {
+            "get": {
+                "description": "Возвращает дерево всех комментариев, привязанных к указанному треду",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "threads"
+                ],
+                "summary": "Получить комментарии треда",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Ключ треда",
+                        "name": "key",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Номер страницы для пагинации",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Количество комментариев на странице",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "oldest",
+                            "newest",
+                            "most-replied",
+                            "top-voted",
+                            "best"
+                        ],
+                        "type": "string",
+                        "description": "Порядок комментариев на каждом уровне дерева (по умолчанию oldest)",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Комментарии треда",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_Komilov31_comment-tree_internal_model.CommentsPage"
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "Ссылки на соседние страницы (rel=next, rel=prev)"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "error\":\"could not get comments",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/version": {
+            "get": {
+                "description": "Возвращает версию сборки, коммит, из которого она собрана, и версию Go",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Версия сервиса",
+                "responses": {
+                    "200": {
+                        "description": "Информация о сборке",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_Komilov31_comment-tree_internal_model.BuildInfo"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "github_com_Komilov31_comment-tree_internal_dto.CreateComment": {
+            "type": "object",
+            "properties": {
+                "author": {
+                    "$ref": "#/definitions/github_com_Komilov31_comment-tree_internal_model.Author"
+                },
+                "created_at": {
                     "type": "string"
                 },
                 "id": {
                     "type": "integer"
                 },
+                "language": {
+                    "type": "string"
+                },
                 "parent_id": {
                     "type": "integer"
                 },
                 "text": {
                     "type": "string"
+                },
+                "thread_key": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_Komilov31_comment-tree_internal_dto.Highlight": {
+            "type": "object",
+            "properties": {
+                "max_fragments": {
+                    "type": "integer"
+                },
+                "start_sel": {
+                    "type": "string"
+                },
+                "stop_sel": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_Komilov31_comment-tree_internal_dto.MoveComment": {
+            "type": "object",
+            "properties": {
+                "parent_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_Komilov31_comment-tree_internal_dto.Reaction": {
+            "type": "object",
+            "properties": {
+                "emoji": {
+                    "type": "string"
                 }
             }
         },
+        "github_com_Komilov31_comment-tree_internal_dto.SearchMode": {
+            "type": "string",
+            "enum": [
+                "fulltext",
+                "fuzzy",
+                "both"
+            ],
+            "x-enum-varnames": [
+                "SearchFullText",
+                "SearchFuzzy",
+                "SearchBoth"
+            ]
+        },
         "github_com_Komilov31_comment-tree_internal_dto.SearchText": {
+            "type": "object",
+            "properties": {
+                "author_id": {
+                    "type": "string"
+                },
+                "from": {
+                    "type": "string"
+                },
+                "highlight": {
+                    "$ref": "#/definitions/github_com_Komilov31_comment-tree_internal_dto.Highlight"
+                },
+                "min_score": {
+                    "type": "integer"
+                },
+                "mode": {
+                    "$ref": "#/definitions/github_com_Komilov31_comment-tree_internal_dto.SearchMode"
+                },
+                "roots_only": {
+                    "type": "boolean"
+                },
+                "similarity": {
+                    "type": "number"
+                },
+                "text": {
+                    "type": "string"
+                },
+                "thread_key": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_Komilov31_comment-tree_internal_dto.UpdateComment": {
             "type": "object",
             "properties": {
                 "text": {
@@ -319,26 +1680,295 @@ const docTemplate = `{
                 }
             }
         },
+        "github_com_Komilov31_comment-tree_internal_dto.Vote": {
+            "type": "object",
+            "properties": {
+                "vote": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_Komilov31_comment-tree_internal_model.Author": {
+            "type": "object",
+            "properties": {
+                "avatar_url": {
+                    "type": "string"
+                },
+                "display_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_Komilov31_comment-tree_internal_model.BuildInfo": {
+            "type": "object",
+            "properties": {
+                "commit": {
+                    "type": "string"
+                },
+                "commit_time": {
+                    "type": "string"
+                },
+                "go_version": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "string"
+                }
+            }
+        },
         "github_com_Komilov31_comment-tree_internal_model.Comment": {
             "type": "object",
             "properties": {
+                "ancestors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_Komilov31_comment-tree_internal_model.Comment"
+                    }
+                },
+                "author": {
+                    "$ref": "#/definitions/github_com_Komilov31_comment-tree_internal_model.Author"
+                },
                 "children": {
                     "type": "array",
                     "items": {
                         "$ref": "#/definitions/github_com_Komilov31_comment-tree_internal_model.Comment"
                     }
                 },
+                "children_count": {
+                    "description": "ChildrenCount is the former name of ReplyCount, still returned so\nthat clients of depth-limited trees keep working.\n\nDeprecated: use ReplyCount.",
+                    "type": "integer"
+                },
                 "created_at": {
                     "type": "string"
                 },
+                "deleted": {
+                    "type": "boolean"
+                },
+                "depth": {
+                    "type": "integer"
+                },
+                "descendant_count": {
+                    "type": "integer"
+                },
+                "downvotes": {
+                    "type": "integer"
+                },
+                "edited_at": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "integer"
                 },
+                "language": {
+                    "type": "string"
+                },
+                "last_reply_at": {
+                    "type": "string"
+                },
+                "more": {
+                    "description": "More is only filled for depth-limited trees.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/github_com_Komilov31_comment-tree_internal_model.MoreReplies"
+                        }
+                    ]
+                },
+                "my_vote": {
+                    "type": "integer"
+                },
                 "parent_id": {
                     "type": "integer"
                 },
+                "rank": {
+                    "description": "Rank, Snippet, RootID and Ancestors are only filled for search hits,\nSnippet when highlighting was asked for. Ancestors run from the root\nof the thread down to the parent of the hit and are empty for roots.",
+                    "type": "number"
+                },
+                "reactions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_Komilov31_comment-tree_internal_model.Reaction"
+                    }
+                },
+                "reply_count": {
+                    "description": "ReplyCount counts direct replies, DescendantCount the whole subtree\nand LastReplyAt is the time of its newest comment. They are kept by\nthe database and do not depend on which children were loaded.",
+                    "type": "integer"
+                },
+                "root_id": {
+                    "type": "integer"
+                },
+                "score": {
+                    "type": "integer"
+                },
+                "snippet": {
+                    "type": "string"
+                },
+                "text": {
+                    "type": "string"
+                },
+                "thread_key": {
+                    "type": "string"
+                },
+                "upvotes": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_Komilov31_comment-tree_internal_model.CommentsPage": {
+            "type": "object",
+            "properties": {
+                "has_more": {
+                    "type": "boolean"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_Komilov31_comment-tree_internal_model.Comment"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "next": {
+                    "type": "string"
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "prev": {
+                    "type": "string"
+                },
+                "prev_cursor": {
+                    "type": "string"
+                },
+                "total_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_Komilov31_comment-tree_internal_model.DiffOp": {
+            "type": "object",
+            "properties": {
+                "op": {
+                    "type": "string"
+                },
+                "text": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_Komilov31_comment-tree_internal_model.MoreReplies": {
+            "type": "object",
+            "properties": {
+                "after": {
+                    "type": "string"
+                },
+                "next": {
+                    "type": "string"
+                },
+                "remaining": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_Komilov31_comment-tree_internal_model.Reaction": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "emoji": {
+                    "type": "string"
+                },
+                "reacted": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "github_com_Komilov31_comment-tree_internal_model.Readiness": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "expected_migration": {
+                    "type": "integer"
+                },
+                "migration": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_Komilov31_comment-tree_internal_model.Revision": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "diff": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_Komilov31_comment-tree_internal_model.DiffOp"
+                    }
+                },
                 "text": {
                     "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_Komilov31_comment-tree_internal_model.Suggestion": {
+            "type": "object",
+            "properties": {
+                "excerpt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_Komilov31_comment-tree_internal_model.Suggestions": {
+            "type": "object",
+            "properties": {
+                "comments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_Komilov31_comment-tree_internal_model.Suggestion"
+                    }
+                },
+                "terms": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "github_com_Komilov31_comment-tree_internal_model.Votes": {
+            "type": "object",
+            "properties": {
+                "downvotes": {
+                    "type": "integer"
+                },
+                "my_vote": {
+                    "type": "integer"
+                },
+                "score": {
+                    "type": "integer"
+                },
+                "upvotes": {
+                    "type": "integer"
                 }
             }
         }
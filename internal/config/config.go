@@ -23,6 +23,41 @@ func initConfig(path string) *Config {
 		log.Fatal("could not parse config file: ", err)
 	}
 
+	switch cfg.Comments.DeleteMode {
+	case "":
+		cfg.Comments.DeleteMode = "soft"
+	case "soft", "hard":
+	default:
+		log.Fatal("invalid comments.delete_mode: ", cfg.Comments.DeleteMode)
+	}
+
+	if cfg.HttpServer.Timeout <= 0 {
+		cfg.HttpServer.Timeout = 4
+	}
+	if cfg.HttpServer.IdleTimeout <= 0 {
+		cfg.HttpServer.IdleTimeout = 60
+	}
+	if cfg.HttpServer.ShutdownTimeout <= 0 {
+		cfg.HttpServer.ShutdownTimeout = 10
+	}
+
+	if cfg.Trash.RetentionHours <= 0 {
+		cfg.Trash.RetentionHours = 720
+	}
+	if cfg.Trash.PurgeIntervalMinutes <= 0 {
+		cfg.Trash.PurgeIntervalMinutes = 60
+	}
+
+	if len(cfg.Search.Languages) == 0 {
+		cfg.Search.Languages = map[string]string{"ru": "russian"}
+	}
+	if cfg.Search.DefaultLanguage == "" {
+		cfg.Search.DefaultLanguage = "ru"
+	}
+	if _, ok := cfg.Search.Languages[cfg.Search.DefaultLanguage]; !ok {
+		log.Fatal("search.default_language is not one of search.languages: ", cfg.Search.DefaultLanguage)
+	}
+
 	err = godotenv.Load(".env")
 	if err != nil {
 		log.Fatal("could not load .env file: ", err)
@@ -31,5 +66,8 @@ func initConfig(path string) *Config {
 	value, _ := os.LookupEnv("DB_PASSWORD")
 	cfg.Postgres.Password = value
 
+	value, _ = os.LookupEnv("AUTH_PROXY_SECRET")
+	cfg.Auth.ProxySecret = value
+
 	return &cfg
 }
@@ -3,6 +3,10 @@ package config
 type Config struct {
 	Postgres   PostgresConfig   `mapstructure:"postgres"`
 	HttpServer HttpServerConfig `mapstructure:"http_server"`
+	Comments   CommentsConfig   `mapstructure:"comments"`
+	Trash      TrashConfig      `mapstructure:"trash"`
+	Search     SearchConfig     `mapstructure:"search"`
+	Auth       AuthConfig       `mapstructure:"auth"`
 }
 
 type PostgresConfig struct {
@@ -14,7 +18,44 @@ type PostgresConfig struct {
 }
 
 type HttpServerConfig struct {
-	Address     string `mapstructure:"address"`
-	Timeout     int    `mapstructure:"timeout"`
-	IdleTimeout int    `mapstructure:"idle_timeout"`
+	Address string `mapstructure:"address"`
+	// Timeout is the deadline of a request in seconds, after which its
+	// database queries are cancelled. It also bounds reading the request
+	// and writing the response.
+	Timeout int `mapstructure:"timeout"`
+	// IdleTimeout is how long in seconds a keep-alive connection may wait
+	// for the next request.
+	IdleTimeout int `mapstructure:"idle_timeout"`
+	// ShutdownTimeout is the grace period in seconds given to in-flight
+	// requests when the server is stopped.
+	ShutdownTimeout int `mapstructure:"shutdown_timeout"`
+}
+
+type CommentsConfig struct {
+	// DeleteMode is either "soft" (leave a tombstone that keeps replies
+	// visible) or "hard" (admins remove the comment together with its
+	// replies, other users still leave a tombstone).
+	DeleteMode string `mapstructure:"delete_mode"`
+}
+
+type TrashConfig struct {
+	RetentionHours       int `mapstructure:"retention_hours"`
+	PurgeIntervalMinutes int `mapstructure:"purge_interval_minutes"`
+}
+
+type SearchConfig struct {
+	// Languages maps the language codes of comments to the Postgres text
+	// search configurations their search vectors are built with.
+	Languages map[string]string `mapstructure:"languages"`
+	// DefaultLanguage is used for comments whose language was neither
+	// supplied nor detected. It must be one of Languages.
+	DefaultLanguage string `mapstructure:"default_language"`
+}
+
+type AuthConfig struct {
+	// ProxySecret is shared with the authenticating gateway, which sends
+	// it along with the identity headers. It is read from the
+	// AUTH_PROXY_SECRET environment variable; when it is empty, identity
+	// headers are ignored and every caller is anonymous.
+	ProxySecret string `mapstructure:"proxy_secret"`
 }
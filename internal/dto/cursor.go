@@ -0,0 +1,40 @@
+package dto
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor points at a comment in the (created_at, id) keyset ordering. It is
+// handed to clients as an opaque string.
+type Cursor struct {
+	CreatedAt time.Time
+	ID        int
+}
+
+func (c Cursor) Encode() string {
+	raw := fmt.Sprintf("%d:%d", c.CreatedAt.UnixMicro(), c.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func DecodeCursor(token string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var micro int64
+	var id int
+	if _, err := fmt.Sscanf(string(raw), "%d:%d", &micro, &id); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return &Cursor{
+		CreatedAt: time.UnixMicro(micro).UTC(),
+		ID:        id,
+	}, nil
+}
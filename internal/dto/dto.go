@@ -1,20 +1,96 @@
 package dto
 
-import "time"
+import (
+	"time"
+
+	"github.com/Komilov31/comment-tree/internal/model"
+)
+
+// DefaultThreadKey is used when the client does not specify a thread.
+const DefaultThreadKey = "default"
+
+// Default number of levels of siblings and replies in a comment context.
+const (
+	DefaultContextUp   = 1
+	DefaultContextDown = 3
+)
 
 type CreateComment struct {
-	ID        int       `json:"id"`
-	ParentID  *int      `json:"parent_id"`
-	Text      string    `json:"text"`
-	CreatedAt time.Time `json:"created_at"`
+	ID        int           `json:"id"`
+	ParentID  *int          `json:"parent_id"`
+	ThreadKey string        `json:"thread_key"`
+	Author    *model.Author `json:"author"`
+	Text      string        `json:"text"`
+	Language  string        `json:"language"`
+	CreatedAt time.Time     `json:"created_at"`
+
+	// SearchConfig is the text search configuration of Language.
+	SearchConfig string `json:"-"`
+}
+
+// Requester identifies the caller of an operation that needs authorization.
+type Requester struct {
+	ID    string
+	Admin bool
+}
+
+type UpdateComment struct {
+	Text string `json:"text"`
 }
 
 type CommentsPagination struct {
-	ParentID int
-	Page     int
-	Limit    int
+	ThreadKey   string
+	ParentID    int
+	Page        int
+	Limit       int
+	After       *Cursor
+	Before      *Cursor
+	MaxDepth    int
+	MaxChildren int
+	Sort        SortOrder
+	ViewerID    string
+}
+
+// MoveComment is the new place of a moved comment: the comment to put it
+// under, or nil to make it a root.
+type MoveComment struct {
+	ParentID *int `json:"parent_id"`
 }
 
+// CommentContext selects the pruned tree around comment ID: the chain of
+// its ancestors, all replies of its Up nearest ancestors (the siblings of
+// the comment and of its parents) and Down levels of its own replies.
+type CommentContext struct {
+	ID       int
+	Up       int
+	Down     int
+	Sort     SortOrder
+	ViewerID string
+}
+
+// SearchText is a search query in websearch_to_tsquery syntax: "quoted
+// phrases", or and -excluded words. Mode switches to trigram matching with
+// the Similarity threshold. The optional filters narrow the hits down to a
+// creation date range, an author, a minimum score or roots only.
 type SearchText struct {
-	Text string `json:"text"`
+	ThreadKey  string     `json:"thread_key"`
+	Text       string     `json:"text"`
+	Mode       SearchMode `json:"mode"`
+	Similarity *float64   `json:"similarity"`
+	Highlight  *Highlight `json:"highlight"`
+	From       *time.Time `json:"from"`
+	To         *time.Time `json:"to"`
+	AuthorID   string     `json:"author_id"`
+	MinScore   *int       `json:"min_score"`
+	RootsOnly  bool       `json:"roots_only"`
+	Page       int        `json:"-"`
+	Limit      int        `json:"-"`
+	Lang       string     `json:"-"`
+	Sort       SortOrder  `json:"-"`
+	ViewerID   string     `json:"-"`
+
+	// HighlightOptions are the ts_headline options built from Highlight
+	// and SearchConfigs the text search configurations to match with.
+	HighlightOptions string   `json:"-"`
+	SearchConfigs    []string `json:"-"`
 }
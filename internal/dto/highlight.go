@@ -0,0 +1,50 @@
+package dto
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrInvalidHighlight = errors.New("invalid highlight")
+
+const (
+	defaultStartSel = "<b>"
+	defaultStopSel  = "</b>"
+	maxSelLength    = 32
+	maxFragments    = 10
+)
+
+// Highlight asks search for a snippet of every hit with the matched words
+// wrapped in StartSel and StopSel. MaxFragments > 0 builds the snippet from
+// up to that many separate excerpts instead of a single one.
+type Highlight struct {
+	StartSel     string `json:"start_sel"`
+	StopSel      string `json:"stop_sel"`
+	MaxFragments int    `json:"max_fragments"`
+}
+
+// Options returns the ts_headline options for h, filling in the default
+// tags, or ErrInvalidHighlight when a tag is too long or contains a quote
+// or a comma, or MaxFragments is out of range.
+func (h Highlight) Options() (string, error) {
+	startSel, stopSel := h.StartSel, h.StopSel
+	if startSel == "" {
+		startSel = defaultStartSel
+	}
+	if stopSel == "" {
+		stopSel = defaultStopSel
+	}
+
+	for _, sel := range []string{startSel, stopSel} {
+		if len(sel) > maxSelLength || strings.ContainsAny(sel, `",`) {
+			return "", ErrInvalidHighlight
+		}
+	}
+
+	if h.MaxFragments < 0 || h.MaxFragments > maxFragments {
+		return "", ErrInvalidHighlight
+	}
+
+	return fmt.Sprintf(`StartSel="%s", StopSel="%s", MaxFragments=%d`, startSel, stopSel, h.MaxFragments), nil
+}
@@ -0,0 +1,7 @@
+package dto
+
+import "errors"
+
+// ErrInvalidLanguage is returned for a language code that is not declared
+// in the search configuration.
+var ErrInvalidLanguage = errors.New("unsupported language")
@@ -0,0 +1,28 @@
+package dto
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var ErrInvalidReaction = errors.New("invalid reaction")
+
+// maxEmojiLength is generous enough for emoji built from several code
+// points, such as flags and skin tone or family sequences.
+const maxEmojiLength = 16
+
+type Reaction struct {
+	Emoji string `json:"emoji"`
+}
+
+// Normalize returns the trimmed emoji or ErrInvalidReaction when it is
+// empty, too long or contains spaces.
+func (r Reaction) Normalize() (string, error) {
+	emoji := strings.TrimSpace(r.Emoji)
+	if emoji == "" || utf8.RuneCountInString(emoji) > maxEmojiLength || strings.ContainsFunc(emoji, unicode.IsSpace) {
+		return "", ErrInvalidReaction
+	}
+	return emoji, nil
+}
@@ -0,0 +1,56 @@
+package dto
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrInvalidSearchFilter = errors.New("invalid search filter")
+
+// SearchMode selects how search matches the text of comments. The zero
+// value is SearchFullText.
+type SearchMode string
+
+const (
+	// SearchFullText matches whole words through the search vector.
+	SearchFullText SearchMode = "fulltext"
+	// SearchFuzzy matches words by trigram similarity, so that partial
+	// words, usernames and typos are found too.
+	SearchFuzzy SearchMode = "fuzzy"
+	// SearchBoth returns the hits of both modes ranked by the sum of
+	// their ts_rank and similarity.
+	SearchBoth SearchMode = "both"
+)
+
+// DefaultSimilarity is the word similarity threshold of fuzzy search, the
+// default of pg_trgm.
+const DefaultSimilarity = 0.6
+
+// Threshold returns the word similarity a fuzzy hit must reach.
+func (s SearchText) Threshold() float64 {
+	if s.Similarity == nil {
+		return DefaultSimilarity
+	}
+	return *s.Similarity
+}
+
+// ValidateFilters returns ErrInvalidSearchFilter when the date range of s
+// ends before it starts, its mode is unknown or its similarity threshold
+// is outside of [0, 1].
+func (s SearchText) ValidateFilters() error {
+	if s.From != nil && s.To != nil && s.To.Before(*s.From) {
+		return fmt.Errorf("%w: to is before from", ErrInvalidSearchFilter)
+	}
+
+	switch s.Mode {
+	case "", SearchFullText, SearchFuzzy, SearchBoth:
+	default:
+		return fmt.Errorf("%w: mode %q, expected %s, %s or %s", ErrInvalidSearchFilter, s.Mode, SearchFullText, SearchFuzzy, SearchBoth)
+	}
+
+	if s.Similarity != nil && (*s.Similarity < 0 || *s.Similarity > 1) {
+		return fmt.Errorf("%w: similarity must be between 0 and 1", ErrInvalidSearchFilter)
+	}
+
+	return nil
+}
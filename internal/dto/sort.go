@@ -0,0 +1,36 @@
+package dto
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrInvalidSort = errors.New("invalid sort")
+
+// SortOrder orders the roots of a tree and the replies of every comment in
+// it. The zero value keeps the default order of the endpoint.
+type SortOrder string
+
+const (
+	SortOldest      SortOrder = "oldest"
+	SortNewest      SortOrder = "newest"
+	SortMostReplied SortOrder = "most-replied"
+	SortTopVoted    SortOrder = "top-voted"
+	SortBest        SortOrder = "best"
+)
+
+func ParseSortOrder(value string) (SortOrder, error) {
+	switch order := SortOrder(value); order {
+	case "", SortOldest, SortNewest, SortMostReplied, SortTopVoted, SortBest:
+		return order, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrInvalidSort, value)
+	}
+}
+
+// Chronological reports whether o orders comments by their creation time,
+// the only order in which the replies of a comment can be limited and
+// continued with a cursor.
+func (o SortOrder) Chronological() bool {
+	return o == "" || o == SortOldest || o == SortNewest
+}
@@ -0,0 +1,23 @@
+package dto
+
+// Default and largest number of comments and terms in a suggestion.
+const (
+	DefaultSuggestLimit = 5
+	MaxSuggestLimit     = 10
+)
+
+// Suggest asks for autocomplete suggestions for Query, the text typed into
+// the search box so far.
+type Suggest struct {
+	ThreadKey string
+	Query     string
+	Limit     int
+	Lang      string
+
+	// TSQuery is the prefix tsquery built from the words of Query, Prefix
+	// its last, possibly unfinished word and SearchConfigs the text search
+	// configurations to match with.
+	TSQuery       string
+	Prefix        string
+	SearchConfigs []string
+}
@@ -0,0 +1,32 @@
+package dto
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrInvalidVote = errors.New("invalid vote")
+
+const (
+	VoteUp    = "up"
+	VoteDown  = "down"
+	VoteClear = "clear"
+)
+
+type Vote struct {
+	Vote string `json:"vote"`
+}
+
+// Value returns the stored value of the vote: 1, -1 or 0 to clear it.
+func (v Vote) Value() (int, error) {
+	switch v.Vote {
+	case VoteUp:
+		return 1, nil
+	case VoteDown:
+		return -1, nil
+	case VoteClear:
+		return 0, nil
+	default:
+		return 0, fmt.Errorf("%w: %q, expected %s, %s or %s", ErrInvalidVote, v.Vote, VoteUp, VoteDown, VoteClear)
+	}
+}
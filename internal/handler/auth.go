@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/url"
+	"strings"
+
+	"github.com/Komilov31/comment-tree/internal/dto"
+	"github.com/Komilov31/comment-tree/internal/model"
+	"github.com/wb-go/wbf/ginext"
+)
+
+// The service is expected to run behind an authenticating gateway that
+// forwards the caller identity in these headers. Values may be URL-encoded
+// so that non-ASCII display names survive the trip.
+const (
+	userIDHeader     = "X-User-Id"
+	userNameHeader   = "X-User-Name"
+	userAvatarHeader = "X-User-Avatar"
+	userRoleHeader   = "X-User-Role"
+)
+
+// proxySecretHeader carries the secret shared with the gateway, which
+// proves that the identity headers were set by it and not by the client.
+const proxySecretHeader = "X-Proxy-Secret"
+
+var identityHeaders = []string{userIDHeader, userNameHeader, userAvatarHeader, userRoleHeader}
+
+// TrustedProxy drops the identity headers of every request that does not
+// carry secret in the X-Proxy-Secret header, so that clients reaching the
+// service directly cannot act as another user or as an admin. An empty
+// secret trusts no request and leaves every caller anonymous.
+func TrustedProxy(secret string) ginext.HandlerFunc {
+	return func(c *ginext.Context) {
+		given := c.GetHeader(proxySecretHeader)
+		trusted := secret != "" && subtle.ConstantTimeCompare([]byte(given), []byte(secret)) == 1
+
+		c.Request.Header.Del(proxySecretHeader)
+		if !trusted {
+			for _, header := range identityHeaders {
+				c.Request.Header.Del(header)
+			}
+		}
+
+		c.Next()
+	}
+}
+
+const adminRole = "admin"
+
+var (
+	errUnauthenticated = errors.New("authentication required")
+	errAdminRequired   = errors.New("admin role required")
+)
+
+func currentUser(c *ginext.Context) (*model.Author, error) {
+	id := headerValue(c, userIDHeader)
+	if id == "" {
+		return nil, errUnauthenticated
+	}
+
+	author := &model.Author{
+		ID:          id,
+		DisplayName: headerValue(c, userNameHeader),
+	}
+	if author.DisplayName == "" {
+		author.DisplayName = id
+	}
+
+	if avatar := headerValue(c, userAvatarHeader); avatar != "" {
+		author.AvatarURL = &avatar
+	}
+
+	return author, nil
+}
+
+func currentRequester(c *ginext.Context) (dto.Requester, error) {
+	author, err := currentUser(c)
+	if err != nil {
+		return dto.Requester{}, err
+	}
+
+	return dto.Requester{
+		ID:    author.ID,
+		Admin: strings.EqualFold(headerValue(c, userRoleHeader), adminRole),
+	}, nil
+}
+
+// viewerID returns the caller id on endpoints where authentication is
+// optional, or an empty string for anonymous callers.
+func viewerID(c *ginext.Context) string {
+	return headerValue(c, userIDHeader)
+}
+
+func headerValue(c *ginext.Context, key string) string {
+	value := c.GetHeader(key)
+	if decoded, err := url.QueryUnescape(value); err == nil {
+		value = decoded
+	}
+	return strings.TrimSpace(value)
+}
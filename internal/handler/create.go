@@ -11,16 +11,29 @@ import (
 )
 
 // @Summary Создать комментарий
-// @Description Создает новый комментарий в системе
+// @Description Создает новый комментарий в системе. Язык комментария (language) определяет, как его текст индексируется для поиска; если он не указан, язык определяется по тексту
 // @Tags comments
 // @Accept json
 // @Produce json
+// @Param X-User-Id header string true "ID автора"
+// @Param X-User-Name header string false "Отображаемое имя автора"
+// @Param X-User-Avatar header string false "URL аватара автора"
 // @Param comment body dto.CreateComment true "Данные для создания комментария"
 // @Success 200 {object} dto.CreateComment "Успешно созданный комментарий"
-// @Failure 400 {object} map[string]string "error":"invalid payload"
+// @Failure 400 {object} map[string]string "error":"invalid payload" or "unsupported language"
+// @Failure 401 {object} map[string]string "error":"authentication required"
 // @Failure 500 {object} map[string]string "error":"could not create comment in db"
 // @Router /comments [post]
 func (h *Handler) CreateComment(c *ginext.Context) {
+	author, err := currentUser(c)
+	if err != nil {
+		zlog.Logger.Error().Msgf("could not identify comment author: %s", err.Error())
+		c.JSON(http.StatusUnauthorized, ginext.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
 	comment := new(dto.CreateComment)
 	if err := c.BindJSON(comment); err != nil {
 		zlog.Logger.Error().Msgf("could not unmarshal request body to model: %s", err.Error())
@@ -29,9 +42,19 @@ func (h *Handler) CreateComment(c *ginext.Context) {
 		})
 		return
 	}
+	comment.ThreadKey = threadKeyOrDefault(comment.ThreadKey)
+	comment.Author = author
 
-	comment, err := h.service.CreateComment(*comment)
+	comment, err = h.service.CreateComment(c.Request.Context(), *comment)
 	if err != nil {
+		if errors.Is(err, dto.ErrInvalidLanguage) {
+			zlog.Logger.Error().Msgf("could not create comment: %s", err.Error())
+			c.JSON(http.StatusBadRequest, ginext.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
 		if errors.Is(err, repository.ErrInvalidParenID) {
 			zlog.Logger.Error().Msgf("could not create comment in db: %s", err.Error())
 			c.JSON(http.StatusBadRequest, ginext.H{
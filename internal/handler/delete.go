@@ -1,25 +1,29 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
-	"strconv"
 
+	"github.com/Komilov31/comment-tree/internal/repository"
 	"github.com/wb-go/wbf/ginext"
 	"github.com/wb-go/wbf/zlog"
 )
 
 // @Summary Удалить комментарий по ID
-// @Description Удаляет комментарий по его уникальному идентификатору
+// @Description Удаляет комментарий по его уникальному идентификатору. В зависимости от настройки comments.delete_mode комментарий либо превращается в "[deleted]" с сохранением ответов, либо удаляется вместе со всеми ответами. Вместе с ответами комментарий удаляет только администратор, у остальных пользователей он всегда превращается в "[deleted]"
 // @Tags comments
 // @Accept json
 // @Produce json
 // @Param id path int true "ID комментария для удаления"
+// @Param X-User-Id header string true "ID автора"
 // @Success 200 {object} map[string]string "status":"successfully deleted comment"
 // @Failure 400 {object} map[string]string "error":"invalid id was provided" or "could not delete comment from db"
+// @Failure 401 {object} map[string]string "error":"authentication required"
+// @Failure 403 {object} map[string]string "error":"comment belongs to another author"
+// @Failure 404 {object} map[string]string "error":"there is not comment with such id"
 // @Router /comments/{id} [delete]
 func (h *Handler) DeleteCommentById(c *ginext.Context) {
-	id := c.Param("id")
-	commentId, err := strconv.Atoi(id)
+	commentId, err := commentIDParam(c)
 	if err != nil {
 		zlog.Logger.Error().Msgf("invalid id was provided: %s", err.Error())
 		c.JSON(http.StatusBadRequest, ginext.H{
@@ -28,15 +32,151 @@ func (h *Handler) DeleteCommentById(c *ginext.Context) {
 		return
 	}
 
-	if err := h.service.DeleteCommentById(commentId); err != nil {
-		zlog.Logger.Error().Msgf("could not delete comment from db: %s", err.Error())
+	requester, err := currentRequester(c)
+	if err != nil {
+		zlog.Logger.Error().Msgf("could not identify requester: %s", err.Error())
+		c.JSON(http.StatusUnauthorized, ginext.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := h.service.DeleteCommentById(c.Request.Context(), commentId, requester); err != nil {
+		writeDeleteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ginext.H{
+		"status": "successfully deleted comment",
+	})
+}
+
+// @Summary Удалить комментарий вместе с ответами (администратор)
+// @Description Безвозвратно удаляет комментарий и все вложенные ответы независимо от comments.delete_mode. Доступно только администраторам
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path int true "ID комментария для удаления"
+// @Param X-User-Id header string true "ID пользователя"
+// @Param X-User-Role header string true "Роль пользователя (admin)"
+// @Success 200 {object} map[string]string "status":"successfully deleted comment"
+// @Failure 400 {object} map[string]string "error":"invalid id was provided" or "could not delete comment from db"
+// @Failure 401 {object} map[string]string "error":"authentication required"
+// @Failure 403 {object} map[string]string "error":"admin role required"
+// @Failure 404 {object} map[string]string "error":"there is not comment with such id"
+// @Router /admin/comments/{id} [delete]
+func (h *Handler) HardDeleteCommentById(c *ginext.Context) {
+	commentId, err := commentIDParam(c)
+	if err != nil {
+		zlog.Logger.Error().Msgf("invalid id was provided: %s", err.Error())
 		c.JSON(http.StatusBadRequest, ginext.H{
-			"error": "could not delete comment from db: " + err.Error(),
+			"error": "invalid id was provided",
+		})
+		return
+	}
+
+	requester, err := currentRequester(c)
+	if err != nil {
+		zlog.Logger.Error().Msgf("could not identify requester: %s", err.Error())
+		c.JSON(http.StatusUnauthorized, ginext.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if !requester.Admin {
+		zlog.Logger.Error().Msgf("user %s tried to hard delete comment %d", requester.ID, commentId)
+		c.JSON(http.StatusForbidden, ginext.H{
+			"error": errAdminRequired.Error(),
 		})
 		return
 	}
 
+	if err := h.service.HardDeleteCommentById(c.Request.Context(), commentId, requester); err != nil {
+		writeDeleteError(c, err)
+		return
+	}
+
 	c.JSON(http.StatusOK, ginext.H{
 		"status": "successfully deleted comment",
 	})
 }
+
+func writeDeleteError(c *ginext.Context, err error) {
+	zlog.Logger.Error().Msgf("could not delete comment from db: %s", err.Error())
+	switch {
+	case errors.Is(err, repository.ErrNotSuchComment):
+		c.JSON(http.StatusNotFound, ginext.H{
+			"error": err.Error(),
+		})
+	case errors.Is(err, repository.ErrNotAuthor):
+		c.JSON(http.StatusForbidden, ginext.H{
+			"error": err.Error(),
+		})
+	default:
+		c.JSON(http.StatusBadRequest, ginext.H{
+			"error": "could not delete comment from db: " + err.Error(),
+		})
+	}
+}
+
+// @Summary Восстановить удаленный комментарий
+// @Description Отменяет удаление комментария: мягко удаленный комментарий снова становится видимым, а удаленный вместе с ответами возвращается из корзины со всеми этими ответами. ID и даты создания сохраняются. Восстановить может автор комментария или администратор
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param id path int true "ID удаленного комментария"
+// @Param X-User-Id header string true "ID автора"
+// @Success 200 {object} map[string]string "status":"successfully restored comment"
+// @Failure 400 {object} map[string]string "error":"invalid id was provided" or "there is not parent comment with provided id"
+// @Failure 401 {object} map[string]string "error":"authentication required"
+// @Failure 403 {object} map[string]string "error":"comment belongs to another author"
+// @Failure 404 {object} map[string]string "error":"there is not deleted comment with such id"
+// @Failure 500 {object} map[string]string "error":"could not restore comment"
+// @Router /comments/{id}/restore [post]
+func (h *Handler) RestoreCommentById(c *ginext.Context) {
+	commentId, err := commentIDParam(c)
+	if err != nil {
+		zlog.Logger.Error().Msgf("invalid id was provided: %s", err.Error())
+		c.JSON(http.StatusBadRequest, ginext.H{
+			"error": "invalid id was provided",
+		})
+		return
+	}
+
+	requester, err := currentRequester(c)
+	if err != nil {
+		zlog.Logger.Error().Msgf("could not identify requester: %s", err.Error())
+		c.JSON(http.StatusUnauthorized, ginext.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := h.service.RestoreCommentById(c.Request.Context(), commentId, requester); err != nil {
+		zlog.Logger.Error().Msgf("could not restore comment: %s", err.Error())
+		switch {
+		case errors.Is(err, repository.ErrNotInTrash):
+			c.JSON(http.StatusNotFound, ginext.H{
+				"error": err.Error(),
+			})
+		case errors.Is(err, repository.ErrNotAuthor):
+			c.JSON(http.StatusForbidden, ginext.H{
+				"error": err.Error(),
+			})
+		case errors.Is(err, repository.ErrInvalidParenID):
+			c.JSON(http.StatusBadRequest, ginext.H{
+				"error": "restore the parent comment first: " + err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, ginext.H{
+				"error": "could not restore comment: " + err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, ginext.H{
+		"status": "successfully restored comment",
+	})
+}
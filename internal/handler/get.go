@@ -1,23 +1,37 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/Komilov31/comment-tree/internal/dto"
 	_ "github.com/Komilov31/comment-tree/internal/model"
+	"github.com/Komilov31/comment-tree/internal/repository"
 	"github.com/wb-go/wbf/ginext"
 	"github.com/wb-go/wbf/zlog"
 )
 
 // @Summary Получить комментарии
-// @Description Получает комментарии с пагинацией и по ID родительского комментария
+// @Description Получает комментарии с пагинацией и по ID родительского комментария.
+// @Description Если передан page, ответ содержит страницу, общее количество комментариев (total_count) и ссылки next/prev.
+// @Description Если передан limit без page или курсор after/before, используется пагинация по курсору (next_cursor/prev_cursor).
+// @Description Без page и limit возвращается массив с деревом комментария parent.
+// @Description max_depth и max_children ограничивают глубину дерева и количество загружаемых ответов каждого комментария.
+// @Description У комментариев с незагруженными ответами есть поле more со ссылкой next на следующую порцию ответов (after в этом режиме — курсор среди прямых ответов parent).
 // @Tags comments
 // @Accept json
 // @Produce json
+// @Param thread query string false "Ключ треда (по умолчанию default)"
 // @Param parent query int false "ID родительского комментария"
 // @Param page query int false "Номер страницы для пагинации"
 // @Param limit query int false "Количество комментариев на странице"
-// @Success 200 {array} model.Comment "Список комментариев"
+// @Param sort query string false "Порядок комментариев на каждом уровне дерева (по умолчанию oldest)" Enums(oldest, newest, most-replied, top-voted, best)
+// @Param after query string false "Курсор: вернуть комментарии после него (next_cursor предыдущей страницы)"
+// @Param before query string false "Курсор: вернуть комментарии перед ним (prev_cursor предыдущей страницы)"
+// @Param max_depth query int false "Максимальная глубина дерева относительно parent (0 — без ограничения)"
+// @Param max_children query int false "Максимальное количество ответов каждого комментария (0 — без ограничения), совместимо только с sort oldest и newest"
+// @Success 200 {object} model.CommentsPage "Страница комментариев"
+// @Header 200 {string} Link "Ссылки на соседние страницы (rel=next, rel=prev)"
 // @Failure 400 {object} map[string]string "error":"invalid payload" or "invalid id"
 // @Failure 500 {object} map[string]string "error":"could not get comments"
 // @Router /comments [get]
@@ -31,12 +45,24 @@ func (h *Handler) GetComments(c *ginext.Context) {
 		return
 	}
 
-	if config.Page == 0 && config.Limit == 0 {
-		h.getCommentsById(config.ParentID, c)
+	config.ViewerID = viewerID(c)
+
+	if config.Page == 0 && (config.MaxDepth != 0 || config.MaxChildren != 0) {
+		h.getCommentsTree(*config, c)
+		return
+	}
+
+	if config.Page == 0 && (config.Limit != 0 || config.After != nil || config.Before != nil) {
+		h.getCommentsByCursor(*config, c)
 		return
 	}
 
-	comments, err := h.service.GetCommentsPaginated(*config)
+	if config.Page == 0 {
+		h.getCommentsById(*config, c)
+		return
+	}
+
+	page, err := h.service.GetCommentsPaginated(c.Request.Context(), *config)
 	if err != nil {
 		zlog.Logger.Error().Msgf("could not get comments paginated: %s", err.Error())
 		c.JSON(http.StatusInternalServerError, ginext.H{
@@ -45,17 +71,161 @@ func (h *Handler) GetComments(c *ginext.Context) {
 		return
 	}
 
+	writePage(c, page)
+}
+
+// @Summary Получить комментарий
+// @Description Возвращает один комментарий без ответов
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param id path int true "ID комментария"
+// @Success 200 {object} model.Comment "Комментарий"
+// @Failure 400 {object} map[string]string "error":"invalid id was provided"
+// @Failure 404 {object} map[string]string "error":"there is not comment with such id"
+// @Failure 500 {object} map[string]string "error":"could not get comment"
+// @Router /comments/{id} [get]
+func (h *Handler) GetCommentById(c *ginext.Context) {
+	commentId, err := commentIDParam(c)
+	if err != nil {
+		zlog.Logger.Error().Msgf("invalid id was provided: %s", err.Error())
+		c.JSON(http.StatusBadRequest, ginext.H{
+			"error": "invalid id was provided",
+		})
+		return
+	}
+
+	comment, err := h.service.GetCommentById(c.Request.Context(), commentId, viewerID(c))
+	if err != nil {
+		zlog.Logger.Error().Msgf("could not get comment: %s", err.Error())
+		if errors.Is(err, repository.ErrNotSuchComment) {
+			c.JSON(http.StatusNotFound, ginext.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, ginext.H{
+			"error": "could not get comment: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, comment)
+}
+
+// @Summary Получить предков комментария
+// @Description Возвращает цепочку комментариев от корня треда до родителя указанного комментария. Для корневого комментария возвращается пустой массив
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param id path int true "ID комментария"
+// @Success 200 {array} model.Comment "Предки комментария, начиная с корня"
+// @Failure 400 {object} map[string]string "error":"invalid id was provided"
+// @Failure 404 {object} map[string]string "error":"there is not comment with such id"
+// @Failure 500 {object} map[string]string "error":"could not get ancestors"
+// @Router /comments/{id}/ancestors [get]
+func (h *Handler) GetCommentAncestors(c *ginext.Context) {
+	commentId, err := commentIDParam(c)
+	if err != nil {
+		zlog.Logger.Error().Msgf("invalid id was provided: %s", err.Error())
+		c.JSON(http.StatusBadRequest, ginext.H{
+			"error": "invalid id was provided",
+		})
+		return
+	}
+
+	ancestors, err := h.service.GetCommentAncestors(c.Request.Context(), commentId, viewerID(c))
+	if err != nil {
+		zlog.Logger.Error().Msgf("could not get comment ancestors: %s", err.Error())
+		if errors.Is(err, repository.ErrNotSuchComment) {
+			c.JSON(http.StatusNotFound, ginext.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, ginext.H{
+			"error": "could not get ancestors: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, ancestors)
+}
+
+// @Summary Получить комментарий в контексте
+// @Description Возвращает дерево треда, сокращенное до окрестности комментария: цепочку от корня треда до комментария, все ответы его up ближайших предков (соседние комментарии на up уровнях) и down уровней его ответов.
+// @Description Формат ответа такой же, как у дерева комментариев.
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param id path int true "ID комментария"
+// @Param up query int false "Количество уровней с соседними комментариями (по умолчанию 1)"
+// @Param down query int false "Количество уровней ответов на комментарий (по умолчанию 3)"
+// @Param sort query string false "Порядок комментариев на каждом уровне дерева (по умолчанию oldest)" Enums(oldest, newest, most-replied, top-voted, best)
+// @Success 200 {array} model.Comment "Дерево вокруг комментария"
+// @Failure 400 {object} map[string]string "error":"invalid id was provided"
+// @Failure 404 {object} map[string]string "error":"there is not comment with such id"
+// @Failure 500 {object} map[string]string "error":"could not get comment context"
+// @Router /comments/{id}/context [get]
+func (h *Handler) GetCommentContext(c *ginext.Context) {
+	commentId, err := commentIDParam(c)
+	if err != nil {
+		zlog.Logger.Error().Msgf("invalid id was provided: %s", err.Error())
+		c.JSON(http.StatusBadRequest, ginext.H{
+			"error": "invalid id was provided",
+		})
+		return
+	}
+
+	config, err := parseContextParameters(c.Request.URL.Query())
+	if err != nil {
+		zlog.Logger.Error().Msg(err.Error())
+		c.JSON(http.StatusBadRequest, ginext.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	config.ID = commentId
+	config.ViewerID = viewerID(c)
+
+	comments, err := h.service.GetCommentContext(c.Request.Context(), *config)
+	if err != nil {
+		zlog.Logger.Error().Msgf("could not get comment context: %s", err.Error())
+		if errors.Is(err, repository.ErrNotSuchComment) {
+			c.JSON(http.StatusNotFound, ginext.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, ginext.H{
+			"error": "could not get comment context: " + err.Error(),
+		})
+		return
+	}
+
 	c.JSON(http.StatusOK, comments)
 }
 
 // @Summary Поиск комментариев по тексту
-// @Description Ищет комментарии, содержащие указанный текст
+// @Description Ищет комментарии, содержащие указанный текст. Без page и limit возвращаются все найденные комментарии
+// @Description Текст поддерживает синтаксис веб-поиска: "фраза в кавычках", or между альтернативами и -слово для исключения. Фильтры from и to ограничивают дату создания, author_id — автора, min_score — минимальный рейтинг, roots_only оставляет только корневые комментарии
+// @Description mode выбирает способ поиска: fulltext (по умолчанию) — по словам, fuzzy — по сходству триграмм, находящему части слов, имена пользователей и опечатки, both — оба способа с суммой релевантностей. similarity задает порог сходства для fuzzy и both (от 0 до 1, по умолчанию 0.6)
+// @Description Найденные комментарии не вкладываются друг в друга: у каждого есть root_id — ID корневого комментария треда и ancestors — цепочка предков от корня до родителя
+// @Description У каждого найденного комментария есть релевантность rank. Если передан highlight, у комментариев есть фрагмент snippet, в котором найденные слова обрамлены тегами start_sel и stop_sel (по умолчанию <b> и </b>), а max_fragments задает количество отдельных фрагментов (0 — один фрагмент, не больше 10)
 // @Tags comments
 // @Accept json
 // @Produce json
 // @Param search body dto.SearchText true "Текст для поиска в комментариях"
-// @Success 200 {array} model.Comment "Найденные комментарии"
-// @Failure 400 {object} map[string]string "error":"invalid payload"
+// @Param page query int false "Номер страницы для пагинации"
+// @Param limit query int false "Количество комментариев на странице"
+// @Param sort query string false "Порядок комментариев на каждом уровне дерева (по умолчанию по релевантности)" Enums(oldest, newest, most-replied, top-voted, best)
+// @Param lang query string false "Язык комментариев для поиска (по умолчанию поиск по всем языкам)"
+// @Success 200 {object} model.CommentsPage "Найденные комментарии"
+// @Header 200 {string} Link "Ссылки на соседние страницы (rel=next, rel=prev)"
+// @Failure 400 {object} map[string]string "error":"invalid payload", "invalid highlight", "invalid search filter" or "unsupported language"
 // @Failure 500 {object} map[string]string "error":"could not get comments"
 // @Router /comments/search [post]
 func (h *Handler) GetCommentsByTextSearch(c *ginext.Context) {
@@ -68,37 +238,115 @@ func (h *Handler) GetCommentsByTextSearch(c *ginext.Context) {
 		return
 	}
 
-	comments, err := h.service.GetCommentsByTextSearch(searchText.Text)
+	config, err := parserQueryParameters(c.Request.URL.Query())
+	if err != nil {
+		zlog.Logger.Error().Msg(err.Error())
+		c.JSON(http.StatusBadRequest, ginext.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	searchText.ThreadKey = threadKeyOrDefault(searchText.ThreadKey)
+	searchText.Page = config.Page
+	searchText.Limit = config.Limit
+	searchText.Sort = config.Sort
+	searchText.Lang = c.Query("lang")
+	searchText.ViewerID = viewerID(c)
+
+	page, err := h.service.GetCommentsByTextSearch(c.Request.Context(), searchText)
 	if err != nil {
 		zlog.Logger.Error().Msgf("could not get comments searched by text: %s", err.Error())
+		if errors.Is(err, dto.ErrInvalidHighlight) || errors.Is(err, dto.ErrInvalidLanguage) ||
+			errors.Is(err, dto.ErrInvalidSearchFilter) {
+			c.JSON(http.StatusBadRequest, ginext.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
 		c.JSON(http.StatusInternalServerError, ginext.H{
 			"error": "could not get comments: " + err.Error(),
 		})
 		return
 	}
 
-	c.JSON(http.StatusOK, comments)
+	writePage(c, page)
 }
 
-// @Summary Получить все комментарии
-// @Description Возвращает полный список всех комментариев без фильтров c пагинацией
+// @Summary Подсказки для поиска
+// @Description Возвращает подсказки для поисковой строки по мере ввода: фрагменты лучших подходящих комментариев и частые слова, начинающиеся с последнего введенного слова. Каждое слово запроса ищется как префикс. Ответы кешируются на сервере на 30 секунд
 // @Tags comments
-// @Accept json
 // @Produce json
-// @Success 200 {array} model.Comment "Все комментарии"
-// @Failure 400 {object} map[string]string "error":"could not get comments"
-// @Router /comments/all [get]
-func (h *Handler) GetAllComments(c *ginext.Context) {
-	comment, err := h.service.GetAllComments()
+// @Param q query string true "Введенный текст"
+// @Param thread query string false "Ключ треда (по умолчанию default)"
+// @Param limit query int false "Количество комментариев и слов (от 1 до 10, по умолчанию 5)"
+// @Param lang query string false "Язык комментариев для поиска (по умолчанию поиск по всем языкам)"
+// @Success 200 {object} model.Suggestions "Подсказки"
+// @Failure 400 {object} map[string]string "error":"invalid limit was provided" or "unsupported language"
+// @Failure 500 {object} map[string]string "error":"could not get suggestions"
+// @Router /comments/suggest [get]
+func (h *Handler) SuggestComments(c *ginext.Context) {
+	suggest, err := parseSuggestParameters(c.Request.URL.Query())
 	if err != nil {
-		zlog.Logger.Error().Msgf("could not get all comments from db: %s", err.Error())
+		zlog.Logger.Error().Msg(err.Error())
 		c.JSON(http.StatusBadRequest, ginext.H{
-			"error": "could not get comments: " + err.Error(),
+			"error": err.Error(),
 		})
 		return
 	}
 
-	c.JSON(http.StatusOK, comment)
+	suggestions, err := h.service.SuggestComments(c.Request.Context(), *suggest)
+	if err != nil {
+		zlog.Logger.Error().Msgf("could not get suggestions: %s", err.Error())
+		if errors.Is(err, dto.ErrInvalidLanguage) {
+			c.JSON(http.StatusBadRequest, ginext.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, ginext.H{
+			"error": "could not get suggestions: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, suggestions)
+}
+
+// @Summary Получить все комментарии
+// @Description Возвращает полный список всех комментариев без фильтров c пагинацией. Без page и limit возвращаются все комментарии треда
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param thread query string false "Ключ треда (по умолчанию default)"
+// @Param page query int false "Номер страницы для пагинации"
+// @Param limit query int false "Количество комментариев на странице"
+// @Param sort query string false "Порядок комментариев на каждом уровне дерева (по умолчанию oldest)" Enums(oldest, newest, most-replied, top-voted, best)
+// @Success 200 {object} model.CommentsPage "Все комментарии"
+// @Header 200 {string} Link "Ссылки на соседние страницы (rel=next, rel=prev)"
+// @Failure 400 {object} map[string]string "error":"could not get comments"
+// @Router /comments/all [get]
+func (h *Handler) GetAllComments(c *ginext.Context) {
+	h.getThreadComments(threadKeyOrDefault(c.Query("thread")), c)
+}
+
+// @Summary Получить комментарии треда
+// @Description Возвращает дерево всех комментариев, привязанных к указанному треду
+// @Tags threads
+// @Accept json
+// @Produce json
+// @Param key path string true "Ключ треда"
+// @Param page query int false "Номер страницы для пагинации"
+// @Param limit query int false "Количество комментариев на странице"
+// @Param sort query string false "Порядок комментариев на каждом уровне дерева (по умолчанию oldest)" Enums(oldest, newest, most-replied, top-voted, best)
+// @Success 200 {object} model.CommentsPage "Комментарии треда"
+// @Header 200 {string} Link "Ссылки на соседние страницы (rel=next, rel=prev)"
+// @Failure 400 {object} map[string]string "error":"could not get comments"
+// @Router /threads/{key}/comments [get]
+func (h *Handler) GetThreadComments(c *ginext.Context) {
+	h.getThreadComments(threadKeyOrDefault(c.Param("key")), c)
 }
 
 // GetMainPage godoc
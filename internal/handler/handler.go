@@ -1,17 +1,33 @@
 package handler
 
 import (
+	"context"
+
 	"github.com/Komilov31/comment-tree/internal/dto"
 	"github.com/Komilov31/comment-tree/internal/model"
 )
 
 type CommentService interface {
-	GetAllComments() ([]*model.Comment, error)
-	GetCommentsById(int) ([]*model.Comment, error)
-	GetCommentsPaginated(dto.CommentsPagination) ([]*model.Comment, error)
-	GetCommentsByTextSearch(string) ([]*model.Comment, error)
-	CreateComment(dto.CreateComment) (*dto.CreateComment, error)
-	DeleteCommentById(int) error
+	GetAllComments(context.Context, dto.CommentsPagination) (*model.CommentsPage, error)
+	GetCommentById(context.Context, int, string) (*model.Comment, error)
+	GetCommentAncestors(context.Context, int, string) ([]model.Comment, error)
+	GetCommentContext(context.Context, dto.CommentContext) ([]*model.Comment, error)
+	GetCommentsById(context.Context, dto.CommentsPagination) ([]*model.Comment, error)
+	GetCommentsPaginated(context.Context, dto.CommentsPagination) (*model.CommentsPage, error)
+	GetCommentsByCursor(context.Context, dto.CommentsPagination) (*model.CommentsPage, error)
+	GetCommentsTree(context.Context, dto.CommentsPagination) ([]*model.Comment, error)
+	GetCommentsByTextSearch(context.Context, dto.SearchText) (*model.CommentsPage, error)
+	SuggestComments(context.Context, dto.Suggest) (*model.Suggestions, error)
+	CreateComment(context.Context, dto.CreateComment) (*dto.CreateComment, error)
+	UpdateComment(context.Context, int, string, dto.UpdateComment) (*model.Comment, error)
+	GetCommentRevisions(context.Context, int) ([]model.Revision, error)
+	DeleteCommentById(context.Context, int, dto.Requester) error
+	HardDeleteCommentById(context.Context, int, dto.Requester) error
+	RestoreCommentById(context.Context, int, dto.Requester) error
+	MoveComment(context.Context, int, dto.Requester, dto.MoveComment) ([]*model.Comment, error)
+	Vote(context.Context, int, string, dto.Vote) (*model.Votes, error)
+	AddReaction(context.Context, int, string, dto.Reaction) ([]model.Reaction, error)
+	RemoveReaction(context.Context, int, string, dto.Reaction) ([]model.Reaction, error)
 }
 
 type Handler struct {
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
@@ -22,33 +23,108 @@ type MockCommentService struct {
 	mock.Mock
 }
 
-func (m *MockCommentService) GetAllComments() ([]*model.Comment, error) {
-	args := m.Called()
-	return args.Get(0).([]*model.Comment), args.Error(1)
+func (m *MockCommentService) GetAllComments(ctx context.Context, config dto.CommentsPagination) (*model.CommentsPage, error) {
+	args := m.Called(config)
+	return args.Get(0).(*model.CommentsPage), args.Error(1)
 }
 
-func (m *MockCommentService) GetCommentsById(id int) ([]*model.Comment, error) {
-	args := m.Called(id)
+func (m *MockCommentService) GetCommentsById(ctx context.Context, config dto.CommentsPagination) ([]*model.Comment, error) {
+	args := m.Called(config)
 	return args.Get(0).([]*model.Comment), args.Error(1)
 }
 
-func (m *MockCommentService) GetCommentsPaginated(config dto.CommentsPagination) ([]*model.Comment, error) {
+func (m *MockCommentService) GetCommentsPaginated(ctx context.Context, config dto.CommentsPagination) (*model.CommentsPage, error) {
 	args := m.Called(config)
-	return args.Get(0).([]*model.Comment), args.Error(1)
+	return args.Get(0).(*model.CommentsPage), args.Error(1)
+}
+
+func (m *MockCommentService) GetCommentsByCursor(ctx context.Context, config dto.CommentsPagination) (*model.CommentsPage, error) {
+	args := m.Called(config)
+	return args.Get(0).(*model.CommentsPage), args.Error(1)
 }
 
-func (m *MockCommentService) GetCommentsByTextSearch(text string) ([]*model.Comment, error) {
-	args := m.Called(text)
+func (m *MockCommentService) GetCommentsTree(ctx context.Context, config dto.CommentsPagination) ([]*model.Comment, error) {
+	args := m.Called(config)
 	return args.Get(0).([]*model.Comment), args.Error(1)
 }
 
-func (m *MockCommentService) CreateComment(comment dto.CreateComment) (*dto.CreateComment, error) {
+func (m *MockCommentService) GetCommentsByTextSearch(ctx context.Context, search dto.SearchText) (*model.CommentsPage, error) {
+	args := m.Called(search)
+	return args.Get(0).(*model.CommentsPage), args.Error(1)
+}
+
+func (m *MockCommentService) CheckReadiness(ctx context.Context, expectedMigration int64) (int64, error) {
+	args := m.Called(expectedMigration)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockCommentService) SuggestComments(ctx context.Context, suggest dto.Suggest) (*model.Suggestions, error) {
+	args := m.Called(suggest)
+	return args.Get(0).(*model.Suggestions), args.Error(1)
+}
+
+func (m *MockCommentService) CreateComment(ctx context.Context, comment dto.CreateComment) (*dto.CreateComment, error) {
 	args := m.Called(comment)
 	return args.Get(0).(*dto.CreateComment), args.Error(1)
 }
 
-func (m *MockCommentService) DeleteCommentById(id int) error {
+func (m *MockCommentService) UpdateComment(ctx context.Context, id int, authorID string, update dto.UpdateComment) (*model.Comment, error) {
+	args := m.Called(id, authorID, update)
+	return args.Get(0).(*model.Comment), args.Error(1)
+}
+
+func (m *MockCommentService) Vote(ctx context.Context, id int, userID string, vote dto.Vote) (*model.Votes, error) {
+	args := m.Called(id, userID, vote)
+	return args.Get(0).(*model.Votes), args.Error(1)
+}
+
+func (m *MockCommentService) GetCommentById(ctx context.Context, id int, viewerID string) (*model.Comment, error) {
+	args := m.Called(id, viewerID)
+	return args.Get(0).(*model.Comment), args.Error(1)
+}
+
+func (m *MockCommentService) GetCommentAncestors(ctx context.Context, id int, viewerID string) ([]model.Comment, error) {
+	args := m.Called(id, viewerID)
+	return args.Get(0).([]model.Comment), args.Error(1)
+}
+
+func (m *MockCommentService) GetCommentContext(ctx context.Context, config dto.CommentContext) ([]*model.Comment, error) {
+	args := m.Called(config)
+	return args.Get(0).([]*model.Comment), args.Error(1)
+}
+
+func (m *MockCommentService) MoveComment(ctx context.Context, id int, requester dto.Requester, move dto.MoveComment) ([]*model.Comment, error) {
+	args := m.Called(id, requester, move)
+	return args.Get(0).([]*model.Comment), args.Error(1)
+}
+
+func (m *MockCommentService) AddReaction(ctx context.Context, id int, userID string, reaction dto.Reaction) ([]model.Reaction, error) {
+	args := m.Called(id, userID, reaction)
+	return args.Get(0).([]model.Reaction), args.Error(1)
+}
+
+func (m *MockCommentService) RemoveReaction(ctx context.Context, id int, userID string, reaction dto.Reaction) ([]model.Reaction, error) {
+	args := m.Called(id, userID, reaction)
+	return args.Get(0).([]model.Reaction), args.Error(1)
+}
+
+func (m *MockCommentService) GetCommentRevisions(ctx context.Context, id int) ([]model.Revision, error) {
 	args := m.Called(id)
+	return args.Get(0).([]model.Revision), args.Error(1)
+}
+
+func (m *MockCommentService) DeleteCommentById(ctx context.Context, id int, requester dto.Requester) error {
+	args := m.Called(id, requester)
+	return args.Error(0)
+}
+
+func (m *MockCommentService) HardDeleteCommentById(ctx context.Context, id int, requester dto.Requester) error {
+	args := m.Called(id, requester)
+	return args.Error(0)
+}
+
+func (m *MockCommentService) RestoreCommentById(ctx context.Context, id int, requester dto.Requester) error {
+	args := m.Called(id, requester)
 	return args.Error(0)
 }
 
@@ -67,6 +143,8 @@ func TestHandler_CreateComment_Success(t *testing.T) {
 	comment := dto.CreateComment{
 		ID:        1,
 		ParentID:  nil,
+		ThreadKey: dto.DefaultThreadKey,
+		Author:    &model.Author{ID: "u1", DisplayName: "Alice"},
 		Text:      "Test comment",
 		CreatedAt: createdAt,
 	}
@@ -77,6 +155,8 @@ func TestHandler_CreateComment_Success(t *testing.T) {
 	body, _ := json.Marshal(comment)
 	req := httptest.NewRequest(http.MethodPost, "/comments", bytes.NewBuffer(body))
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-User-Id", "u1")
+	req.Header.Set("X-User-Name", "Alice")
 	w := httptest.NewRecorder()
 
 	c, _ := gin.CreateTestContext(w)
@@ -97,6 +177,7 @@ func TestHandler_CreateComment_InvalidPayload(t *testing.T) {
 
 	req := httptest.NewRequest(http.MethodPost, "/comments", bytes.NewBufferString("invalid json"))
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-User-Id", "u1")
 	w := httptest.NewRecorder()
 
 	c, _ := gin.CreateTestContext(w)
@@ -113,7 +194,9 @@ func TestHandler_CreateComment_ServiceError(t *testing.T) {
 	handler := New(mockService)
 
 	comment := dto.CreateComment{
-		Text: "Test comment",
+		ThreadKey: dto.DefaultThreadKey,
+		Author:    &model.Author{ID: "u1", DisplayName: "u1"},
+		Text:      "Test comment",
 	}
 
 	mockService.On("CreateComment", comment).Return((*dto.CreateComment)(nil), errors.New("service error"))
@@ -121,6 +204,7 @@ func TestHandler_CreateComment_ServiceError(t *testing.T) {
 	body, _ := json.Marshal(comment)
 	req := httptest.NewRequest(http.MethodPost, "/comments", bytes.NewBuffer(body))
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-User-Id", "u1")
 	w := httptest.NewRecorder()
 
 	c, _ := gin.CreateTestContext(w)
@@ -137,7 +221,9 @@ func TestHandler_CreateComment_InvalidParentID(t *testing.T) {
 	handler := New(mockService)
 
 	comment := dto.CreateComment{
-		Text: "Test comment",
+		ThreadKey: dto.DefaultThreadKey,
+		Author:    &model.Author{ID: "u1", DisplayName: "u1"},
+		Text:      "Test comment",
 	}
 
 	mockService.On("CreateComment", comment).Return((*dto.CreateComment)(nil), repository.ErrInvalidParenID)
@@ -145,6 +231,7 @@ func TestHandler_CreateComment_InvalidParentID(t *testing.T) {
 	body, _ := json.Marshal(comment)
 	req := httptest.NewRequest(http.MethodPost, "/comments", bytes.NewBuffer(body))
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-User-Id", "u1")
 	w := httptest.NewRecorder()
 
 	c, _ := gin.CreateTestContext(w)
@@ -156,15 +243,67 @@ func TestHandler_CreateComment_InvalidParentID(t *testing.T) {
 	mockService.AssertExpectations(t)
 }
 
+func TestHandler_CreateComment_Unauthenticated(t *testing.T) {
+	mockService := &MockCommentService{}
+	handler := New(mockService)
+
+	body, _ := json.Marshal(dto.CreateComment{Text: "Test comment"})
+	req := httptest.NewRequest(http.MethodPost, "/comments", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	handler.CreateComment((*ginext.Context)(c))
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	mockService.AssertNotCalled(t, "CreateComment")
+}
+
+func TestHandler_CreateComment_AuthorFromHeaders(t *testing.T) {
+	mockService := &MockCommentService{}
+	handler := New(mockService)
+
+	avatar := "https://example.com/a.png"
+	expected := dto.CreateComment{
+		ThreadKey: dto.DefaultThreadKey,
+		Author:    &model.Author{ID: "u1", DisplayName: "Алиса", AvatarURL: &avatar},
+		Text:      "Test comment",
+	}
+
+	mockService.On("CreateComment", expected).Return(&expected, nil)
+
+	body, _ := json.Marshal(dto.CreateComment{
+		Author: &model.Author{ID: "mallory", DisplayName: "Mallory"},
+		Text:   "Test comment",
+	})
+	req := httptest.NewRequest(http.MethodPost, "/comments", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-User-Id", "u1")
+	req.Header.Set("X-User-Name", "%D0%90%D0%BB%D0%B8%D1%81%D0%B0")
+	req.Header.Set("X-User-Avatar", avatar)
+	w := httptest.NewRecorder()
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	handler.CreateComment((*ginext.Context)(c))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
 func TestHandler_DeleteCommentById_Success(t *testing.T) {
 	mockService := &MockCommentService{}
 	handler := New(mockService)
 
 	id := "1"
 
-	mockService.On("DeleteCommentById", 1).Return(nil)
+	mockService.On("DeleteCommentById", 1, dto.Requester{ID: "u1"}).Return(nil)
 
 	req := httptest.NewRequest(http.MethodDelete, "/comments/"+id, nil)
+	req.Header.Set("X-User-Id", "u1")
 	w := httptest.NewRecorder()
 
 	c, _ := gin.CreateTestContext(w)
@@ -184,6 +323,7 @@ func TestHandler_DeleteCommentById_InvalidID(t *testing.T) {
 	id := "invalid"
 
 	req := httptest.NewRequest(http.MethodDelete, "/comments/"+id, nil)
+	req.Header.Set("X-User-Id", "u1")
 	w := httptest.NewRecorder()
 
 	c, _ := gin.CreateTestContext(w)
@@ -202,9 +342,10 @@ func TestHandler_DeleteCommentById_ServiceError(t *testing.T) {
 
 	id := "1"
 
-	mockService.On("DeleteCommentById", 1).Return(errors.New("service error"))
+	mockService.On("DeleteCommentById", 1, dto.Requester{ID: "u1"}).Return(errors.New("service error"))
 
 	req := httptest.NewRequest(http.MethodDelete, "/comments/"+id, nil)
+	req.Header.Set("X-User-Id", "u1")
 	w := httptest.NewRecorder()
 
 	c, _ := gin.CreateTestContext(w)
@@ -217,15 +358,212 @@ func TestHandler_DeleteCommentById_ServiceError(t *testing.T) {
 	mockService.AssertExpectations(t)
 }
 
+func TestHandler_DeleteCommentById_Unauthenticated(t *testing.T) {
+	mockService := &MockCommentService{}
+	handler := New(mockService)
+
+	req := httptest.NewRequest(http.MethodDelete, "/comments/1", nil)
+	w := httptest.NewRecorder()
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+
+	handler.DeleteCommentById((*ginext.Context)(c))
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	mockService.AssertNotCalled(t, "DeleteCommentById")
+}
+
+func TestHandler_DeleteCommentById_NotAuthor(t *testing.T) {
+	mockService := &MockCommentService{}
+	handler := New(mockService)
+
+	mockService.On("DeleteCommentById", 1, dto.Requester{ID: "u2"}).Return(repository.ErrNotAuthor)
+
+	req := httptest.NewRequest(http.MethodDelete, "/comments/1", nil)
+	req.Header.Set("X-User-Id", "u2")
+	w := httptest.NewRecorder()
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+
+	handler.DeleteCommentById((*ginext.Context)(c))
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_HardDeleteCommentById_Success(t *testing.T) {
+	mockService := &MockCommentService{}
+	handler := New(mockService)
+
+	mockService.On("HardDeleteCommentById", 1, dto.Requester{ID: "root", Admin: true}).Return(nil)
+
+	req := httptest.NewRequest(http.MethodDelete, "/admin/comments/1", nil)
+	req.Header.Set("X-User-Id", "root")
+	req.Header.Set("X-User-Role", "admin")
+	w := httptest.NewRecorder()
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+
+	handler.HardDeleteCommentById((*ginext.Context)(c))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_HardDeleteCommentById_NotAdmin(t *testing.T) {
+	mockService := &MockCommentService{}
+	handler := New(mockService)
+
+	req := httptest.NewRequest(http.MethodDelete, "/admin/comments/1", nil)
+	req.Header.Set("X-User-Id", "u1")
+	w := httptest.NewRecorder()
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+
+	handler.HardDeleteCommentById((*ginext.Context)(c))
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	mockService.AssertNotCalled(t, "HardDeleteCommentById")
+}
+
+func TestHandler_HardDeleteCommentById_SpoofedRole(t *testing.T) {
+	// without the proxy secret the role header is dropped with the rest of
+	// the identity
+	tests := []struct {
+		name         string
+		headerSecret string
+	}{
+		{name: "role from client", headerSecret: ""},
+		{name: "role with wrong secret", headerSecret: "guess"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockCommentService{}
+			handler := New(mockService)
+
+			w := httptest.NewRecorder()
+			_, engine := gin.CreateTestContext(w)
+			engine.Use(TrustedProxy("s3cret"))
+			engine.DELETE("/admin/comments/:id", handler.HardDeleteCommentById)
+
+			req := httptest.NewRequest(http.MethodDelete, "/admin/comments/1", nil)
+			req.Header.Set("X-User-Id", "mallory")
+			req.Header.Set("X-User-Role", "admin")
+			if tt.headerSecret != "" {
+				req.Header.Set("X-Proxy-Secret", tt.headerSecret)
+			}
+
+			engine.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+			mockService.AssertNotCalled(t, "HardDeleteCommentById")
+		})
+	}
+}
+
+func TestHandler_HardDeleteCommentById_RoleFromProxy(t *testing.T) {
+	mockService := &MockCommentService{}
+	handler := New(mockService)
+
+	mockService.On("HardDeleteCommentById", 1, dto.Requester{ID: "root", Admin: true}).Return(nil)
+
+	w := httptest.NewRecorder()
+	_, engine := gin.CreateTestContext(w)
+	engine.Use(TrustedProxy("s3cret"))
+	engine.DELETE("/admin/comments/:id", handler.HardDeleteCommentById)
+
+	req := httptest.NewRequest(http.MethodDelete, "/admin/comments/1", nil)
+	req.Header.Set("X-User-Id", "root")
+	req.Header.Set("X-User-Role", "admin")
+	req.Header.Set("X-Proxy-Secret", "s3cret")
+
+	engine.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_RestoreCommentById_Success(t *testing.T) {
+	mockService := &MockCommentService{}
+	handler := New(mockService)
+
+	mockService.On("RestoreCommentById", 1, dto.Requester{ID: "u1"}).Return(nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/comments/1/restore", nil)
+	req.Header.Set("X-User-Id", "u1")
+	w := httptest.NewRecorder()
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+
+	handler.RestoreCommentById((*ginext.Context)(c))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_RestoreCommentById_NotInTrash(t *testing.T) {
+	mockService := &MockCommentService{}
+	handler := New(mockService)
+
+	mockService.On("RestoreCommentById", 1, dto.Requester{ID: "u1"}).Return(repository.ErrNotInTrash)
+
+	req := httptest.NewRequest(http.MethodPost, "/comments/1/restore", nil)
+	req.Header.Set("X-User-Id", "u1")
+	w := httptest.NewRecorder()
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+
+	handler.RestoreCommentById((*ginext.Context)(c))
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_RestoreCommentById_ParentMissing(t *testing.T) {
+	mockService := &MockCommentService{}
+	handler := New(mockService)
+
+	mockService.On("RestoreCommentById", 5, dto.Requester{ID: "u1"}).Return(repository.ErrInvalidParenID)
+
+	req := httptest.NewRequest(http.MethodPost, "/comments/5/restore", nil)
+	req.Header.Set("X-User-Id", "u1")
+	w := httptest.NewRecorder()
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Params = gin.Params{{Key: "id", Value: "5"}}
+
+	handler.RestoreCommentById((*ginext.Context)(c))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
+
 func TestHandler_GetAllComments_Success(t *testing.T) {
 	mockService := &MockCommentService{}
 	handler := New(mockService)
 
-	expected := []*model.Comment{
-		{ID: 1, Text: "Comment 1"},
+	total := 1
+	expected := &model.CommentsPage{
+		Items:      []*model.Comment{{ID: 1, Text: "Comment 1"}},
+		Page:       1,
+		TotalCount: &total,
 	}
 
-	mockService.On("GetAllComments").Return(expected, nil)
+	mockService.On("GetAllComments", dto.CommentsPagination{ThreadKey: dto.DefaultThreadKey}).Return(expected, nil)
 
 	req := httptest.NewRequest(http.MethodGet, "/comments/all", nil)
 	w := httptest.NewRecorder()
@@ -236,9 +574,10 @@ func TestHandler_GetAllComments_Success(t *testing.T) {
 	handler.GetAllComments((*ginext.Context)(c))
 
 	assert.Equal(t, http.StatusOK, w.Code)
-	var response []*model.Comment
+	assert.Empty(t, w.Header().Get("Link"))
+	var response model.CommentsPage
 	json.Unmarshal(w.Body.Bytes(), &response)
-	assert.Equal(t, expected, response)
+	assert.Equal(t, expected, &response)
 	mockService.AssertExpectations(t)
 }
 
@@ -246,7 +585,7 @@ func TestHandler_GetAllComments_Error(t *testing.T) {
 	mockService := &MockCommentService{}
 	handler := New(mockService)
 
-	mockService.On("GetAllComments").Return(([]*model.Comment)(nil), errors.New("service error"))
+	mockService.On("GetAllComments", dto.CommentsPagination{ThreadKey: dto.DefaultThreadKey}).Return((*model.CommentsPage)(nil), errors.New("service error"))
 
 	req := httptest.NewRequest(http.MethodGet, "/comments/all", nil)
 	w := httptest.NewRecorder()
@@ -264,12 +603,15 @@ func TestHandler_GetCommentsByTextSearch_Success(t *testing.T) {
 	mockService := &MockCommentService{}
 	handler := New(mockService)
 
-	searchText := dto.SearchText{Text: "search"}
-	expected := []*model.Comment{
-		{ID: 1, Text: "Comment with search"},
+	searchText := dto.SearchText{ThreadKey: dto.DefaultThreadKey, Text: "search"}
+	total := 1
+	expected := &model.CommentsPage{
+		Items:      []*model.Comment{{ID: 1, Text: "Comment with search"}},
+		Page:       1,
+		TotalCount: &total,
 	}
 
-	mockService.On("GetCommentsByTextSearch", searchText.Text).Return(expected, nil)
+	mockService.On("GetCommentsByTextSearch", searchText).Return(expected, nil)
 
 	body, _ := json.Marshal(searchText)
 	req := httptest.NewRequest(http.MethodPost, "/comments/search", bytes.NewBuffer(body))
@@ -282,9 +624,9 @@ func TestHandler_GetCommentsByTextSearch_Success(t *testing.T) {
 	handler.GetCommentsByTextSearch((*ginext.Context)(c))
 
 	assert.Equal(t, http.StatusOK, w.Code)
-	var response []*model.Comment
+	var response model.CommentsPage
 	json.Unmarshal(w.Body.Bytes(), &response)
-	assert.Equal(t, expected, response)
+	assert.Equal(t, expected, &response)
 	mockService.AssertExpectations(t)
 }
 
@@ -309,9 +651,9 @@ func TestHandler_GetCommentsByTextSearch_Error(t *testing.T) {
 	mockService := &MockCommentService{}
 	handler := New(mockService)
 
-	searchText := dto.SearchText{Text: "search"}
+	searchText := dto.SearchText{ThreadKey: dto.DefaultThreadKey, Text: "search"}
 
-	mockService.On("GetCommentsByTextSearch", searchText.Text).Return(([]*model.Comment)(nil), errors.New("service error"))
+	mockService.On("GetCommentsByTextSearch", searchText).Return((*model.CommentsPage)(nil), errors.New("service error"))
 
 	body, _ := json.Marshal(searchText)
 	req := httptest.NewRequest(http.MethodPost, "/comments/search", bytes.NewBuffer(body))
@@ -335,7 +677,7 @@ func TestHandler_GetComments_Success(t *testing.T) {
 		{ID: 1, Text: "Comment 1"},
 	}
 
-	mockService.On("GetCommentsById", 1).Return(expected, nil)
+	mockService.On("GetCommentsById", dto.CommentsPagination{ThreadKey: dto.DefaultThreadKey, ParentID: 1}).Return(expected, nil)
 
 	req := httptest.NewRequest(http.MethodGet, "/comments?parent=1", nil)
 	w := httptest.NewRecorder()
@@ -351,3 +693,1650 @@ func TestHandler_GetComments_Success(t *testing.T) {
 	assert.Equal(t, expected, response)
 	mockService.AssertExpectations(t)
 }
+
+func TestHandler_GetComments_WithThread(t *testing.T) {
+	mockService := &MockCommentService{}
+	handler := New(mockService)
+
+	expected := []*model.Comment{
+		{ID: 1, ThreadKey: "article-42", Text: "Comment 1"},
+	}
+
+	mockService.On("GetCommentsById", dto.CommentsPagination{ThreadKey: "article-42", ParentID: 1}).Return(expected, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/comments?parent=1&thread=article-42", nil)
+	w := httptest.NewRecorder()
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	handler.GetComments((*ginext.Context)(c))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response []*model.Comment
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, expected, response)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_GetThreadComments_Success(t *testing.T) {
+	mockService := &MockCommentService{}
+	handler := New(mockService)
+
+	total := 1
+	expected := &model.CommentsPage{
+		Items:      []*model.Comment{{ID: 1, ThreadKey: "product-7", Text: "Comment 1"}},
+		Page:       1,
+		TotalCount: &total,
+	}
+
+	mockService.On("GetAllComments", dto.CommentsPagination{ThreadKey: "product-7"}).Return(expected, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/threads/product-7/comments", nil)
+	w := httptest.NewRecorder()
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Params = gin.Params{{Key: "key", Value: "product-7"}}
+
+	handler.GetThreadComments((*ginext.Context)(c))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response model.CommentsPage
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, expected, &response)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_GetThreadComments_Error(t *testing.T) {
+	mockService := &MockCommentService{}
+	handler := New(mockService)
+
+	mockService.On("GetAllComments", dto.CommentsPagination{ThreadKey: "product-7"}).Return((*model.CommentsPage)(nil), errors.New("service error"))
+
+	req := httptest.NewRequest(http.MethodGet, "/threads/product-7/comments", nil)
+	w := httptest.NewRecorder()
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Params = gin.Params{{Key: "key", Value: "product-7"}}
+
+	handler.GetThreadComments((*ginext.Context)(c))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_UpdateComment_Success(t *testing.T) {
+	mockService := &MockCommentService{}
+	handler := New(mockService)
+
+	update := dto.UpdateComment{Text: "Edited comment"}
+	editedAt := time.Date(2025, 9, 21, 0, 0, 0, 0, time.UTC)
+	expected := &model.Comment{ID: 1, Text: "Edited comment", EditedAt: &editedAt}
+
+	mockService.On("UpdateComment", 1, "u1", update).Return(expected, nil)
+
+	body, _ := json.Marshal(update)
+	req := httptest.NewRequest(http.MethodPatch, "/comments/1", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-User-Id", "u1")
+	w := httptest.NewRecorder()
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+
+	handler.UpdateComment((*ginext.Context)(c))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response model.Comment
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, expected, &response)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_UpdateComment_Unauthenticated(t *testing.T) {
+	mockService := &MockCommentService{}
+	handler := New(mockService)
+
+	body, _ := json.Marshal(dto.UpdateComment{Text: "Edited comment"})
+	req := httptest.NewRequest(http.MethodPatch, "/comments/1", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+
+	handler.UpdateComment((*ginext.Context)(c))
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	mockService.AssertNotCalled(t, "UpdateComment")
+}
+
+func TestHandler_UpdateComment_NotAuthor(t *testing.T) {
+	mockService := &MockCommentService{}
+	handler := New(mockService)
+
+	update := dto.UpdateComment{Text: "Edited comment"}
+
+	mockService.On("UpdateComment", 1, "u2", update).Return((*model.Comment)(nil), repository.ErrNotAuthor)
+
+	body, _ := json.Marshal(update)
+	req := httptest.NewRequest(http.MethodPatch, "/comments/1", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-User-Id", "u2")
+	w := httptest.NewRecorder()
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+
+	handler.UpdateComment((*ginext.Context)(c))
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_UpdateComment_NotFound(t *testing.T) {
+	mockService := &MockCommentService{}
+	handler := New(mockService)
+
+	update := dto.UpdateComment{Text: "Edited comment"}
+
+	mockService.On("UpdateComment", 42, "u1", update).Return((*model.Comment)(nil), repository.ErrNotSuchComment)
+
+	body, _ := json.Marshal(update)
+	req := httptest.NewRequest(http.MethodPatch, "/comments/42", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-User-Id", "u1")
+	w := httptest.NewRecorder()
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Params = gin.Params{{Key: "id", Value: "42"}}
+
+	handler.UpdateComment((*ginext.Context)(c))
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_GetCommentRevisions_Success(t *testing.T) {
+	mockService := &MockCommentService{}
+	handler := New(mockService)
+
+	expected := []model.Revision{
+		{Version: 1, Text: "first", Diff: []model.DiffOp{{Op: "insert", Text: "first"}}},
+		{Version: 2, Text: "second", Diff: []model.DiffOp{{Op: "delete", Text: "first"}, {Op: "insert", Text: "second"}}},
+	}
+
+	mockService.On("GetCommentRevisions", 1).Return(expected, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/comments/1/revisions", nil)
+	w := httptest.NewRecorder()
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+
+	handler.GetCommentRevisions((*ginext.Context)(c))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response []model.Revision
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, expected, response)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_GetCommentRevisions_NotFound(t *testing.T) {
+	mockService := &MockCommentService{}
+	handler := New(mockService)
+
+	mockService.On("GetCommentRevisions", 42).Return(([]model.Revision)(nil), repository.ErrNotSuchComment)
+
+	req := httptest.NewRequest(http.MethodGet, "/comments/42/revisions", nil)
+	w := httptest.NewRecorder()
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Params = gin.Params{{Key: "id", Value: "42"}}
+
+	handler.GetCommentRevisions((*ginext.Context)(c))
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_GetComments_CursorFirstPage(t *testing.T) {
+	mockService := &MockCommentService{}
+	handler := New(mockService)
+
+	next := dto.Cursor{CreatedAt: time.Date(2025, 9, 20, 0, 0, 0, 0, time.UTC), ID: 2}.Encode()
+	expected := &model.CommentsPage{
+		Items:      []*model.Comment{{ID: 1, Text: "Comment 1"}, {ID: 2, Text: "Comment 2"}},
+		NextCursor: &next,
+	}
+	config := dto.CommentsPagination{ThreadKey: dto.DefaultThreadKey, ParentID: 1, Limit: 2}
+
+	mockService.On("GetCommentsByCursor", config).Return(expected, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/comments?parent=1&limit=2", nil)
+	w := httptest.NewRecorder()
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	handler.GetComments((*ginext.Context)(c))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response model.CommentsPage
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, expected, &response)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_GetComments_CursorAfter(t *testing.T) {
+	mockService := &MockCommentService{}
+	handler := New(mockService)
+
+	after := dto.Cursor{CreatedAt: time.Date(2025, 9, 20, 10, 30, 0, 123456000, time.UTC), ID: 7}
+	config := dto.CommentsPagination{ThreadKey: dto.DefaultThreadKey, After: &after}
+
+	mockService.On("GetCommentsByCursor", config).Return(&model.CommentsPage{}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/comments?after="+after.Encode(), nil)
+	w := httptest.NewRecorder()
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	handler.GetComments((*ginext.Context)(c))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_GetComments_InvalidCursor(t *testing.T) {
+	mockService := &MockCommentService{}
+	handler := New(mockService)
+
+	req := httptest.NewRequest(http.MethodGet, "/comments?after=not-a-cursor", nil)
+	w := httptest.NewRecorder()
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	handler.GetComments((*ginext.Context)(c))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "GetCommentsByCursor")
+}
+
+func TestHandler_GetComments_AfterAndBefore(t *testing.T) {
+	mockService := &MockCommentService{}
+	handler := New(mockService)
+
+	cursor := dto.Cursor{CreatedAt: time.Date(2025, 9, 20, 0, 0, 0, 0, time.UTC), ID: 1}.Encode()
+	req := httptest.NewRequest(http.MethodGet, "/comments?after="+cursor+"&before="+cursor, nil)
+	w := httptest.NewRecorder()
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	handler.GetComments((*ginext.Context)(c))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "GetCommentsByCursor")
+}
+
+func TestHandler_GetComments_PaginatedEnvelope(t *testing.T) {
+	mockService := &MockCommentService{}
+	handler := New(mockService)
+
+	total := 25
+	page := &model.CommentsPage{
+		Items:      []*model.Comment{{ID: 11, Text: "Comment 11"}},
+		Page:       2,
+		Limit:      10,
+		TotalCount: &total,
+		HasMore:    true,
+	}
+	config := dto.CommentsPagination{ThreadKey: dto.DefaultThreadKey, ParentID: 1, Page: 2, Limit: 10}
+
+	mockService.On("GetCommentsPaginated", config).Return(page, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/comments?parent=1&page=2&limit=10", nil)
+	w := httptest.NewRecorder()
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	handler.GetComments((*ginext.Context)(c))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	next := "/comments?limit=10&page=3&parent=1"
+	prev := "/comments?limit=10&page=1&parent=1"
+	assert.Equal(t, `<`+next+`>; rel="next", <`+prev+`>; rel="prev"`, w.Header().Get("Link"))
+
+	var response model.CommentsPage
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, 2, response.Page)
+	assert.Equal(t, 10, response.Limit)
+	assert.Equal(t, &total, response.TotalCount)
+	assert.True(t, response.HasMore)
+	assert.Equal(t, &next, response.Next)
+	assert.Equal(t, &prev, response.Prev)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_GetComments_PaginatedLastPage(t *testing.T) {
+	mockService := &MockCommentService{}
+	handler := New(mockService)
+
+	total := 1
+	page := &model.CommentsPage{
+		Items:      []*model.Comment{{ID: 1, Text: "Comment 1"}},
+		Page:       1,
+		Limit:      10,
+		TotalCount: &total,
+	}
+	config := dto.CommentsPagination{ThreadKey: dto.DefaultThreadKey, ParentID: 1, Page: 1, Limit: 10}
+
+	mockService.On("GetCommentsPaginated", config).Return(page, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/comments?parent=1&page=1&limit=10", nil)
+	w := httptest.NewRecorder()
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	handler.GetComments((*ginext.Context)(c))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Link"))
+
+	var response model.CommentsPage
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.False(t, response.HasMore)
+	assert.Nil(t, response.Next)
+	assert.Nil(t, response.Prev)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_GetComments_CursorLinks(t *testing.T) {
+	mockService := &MockCommentService{}
+	handler := New(mockService)
+
+	next := dto.Cursor{CreatedAt: time.Date(2025, 9, 20, 0, 0, 0, 0, time.UTC), ID: 2}.Encode()
+	page := &model.CommentsPage{
+		Items:      []*model.Comment{{ID: 2, Text: "Comment 2"}},
+		Limit:      1,
+		HasMore:    true,
+		NextCursor: &next,
+	}
+	config := dto.CommentsPagination{ThreadKey: dto.DefaultThreadKey, Limit: 1}
+
+	mockService.On("GetCommentsByCursor", config).Return(page, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/comments?limit=1", nil)
+	w := httptest.NewRecorder()
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	handler.GetComments((*ginext.Context)(c))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	link := "/comments?after=" + next + "&limit=1"
+	assert.Equal(t, `<`+link+`>; rel="next"`, w.Header().Get("Link"))
+
+	var response model.CommentsPage
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, &link, response.Next)
+	assert.Nil(t, response.Prev)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_GetCommentsByTextSearch_Paginated(t *testing.T) {
+	mockService := &MockCommentService{}
+	handler := New(mockService)
+
+	searchText := dto.SearchText{ThreadKey: dto.DefaultThreadKey, Text: "search", Page: 2, Limit: 5}
+	total := 6
+	page := &model.CommentsPage{
+		Items:      []*model.Comment{{ID: 6, Text: "Comment with search"}},
+		Page:       2,
+		Limit:      5,
+		TotalCount: &total,
+	}
+
+	mockService.On("GetCommentsByTextSearch", searchText).Return(page, nil)
+
+	body, _ := json.Marshal(searchText)
+	req := httptest.NewRequest(http.MethodPost, "/comments/search?page=2&limit=5", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	handler.GetCommentsByTextSearch((*ginext.Context)(c))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `</comments/search?limit=5&page=1>; rel="prev"`, w.Header().Get("Link"))
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_GetComments_DepthLimitedTree(t *testing.T) {
+	mockService := &MockCommentService{}
+	handler := New(mockService)
+
+	after := dto.Cursor{CreatedAt: time.Date(2025, 9, 20, 0, 0, 0, 0, time.UTC), ID: 3}.Encode()
+	child := &model.Comment{ID: 3, Text: "Reply", ReplyCount: 4, More: &model.MoreReplies{Remaining: 4}}
+	root := &model.Comment{
+		ID:         1,
+		Text:       "Root",
+		Children:   []*model.Comment{child},
+		ReplyCount: 5,
+		More:       &model.MoreReplies{Remaining: 4, After: &after},
+	}
+	config := dto.CommentsPagination{ThreadKey: dto.DefaultThreadKey, ParentID: 1, MaxDepth: 1, MaxChildren: 1}
+
+	mockService.On("GetCommentsTree", config).Return([]*model.Comment{root}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/comments?parent=1&max_depth=1&max_children=1", nil)
+	w := httptest.NewRecorder()
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	handler.GetComments((*ginext.Context)(c))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response []*model.Comment
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Len(t, response, 1)
+	assert.Equal(t, 5, response[0].ReplyCount)
+	assert.Equal(t, "/comments?after="+after+"&max_children=1&max_depth=1&parent=1", response[0].More.Next)
+	assert.Equal(t, "/comments?max_children=1&max_depth=1&parent=3", response[0].Children[0].More.Next)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_GetComments_DepthLimitedTreeNotFound(t *testing.T) {
+	mockService := &MockCommentService{}
+	handler := New(mockService)
+
+	config := dto.CommentsPagination{ThreadKey: dto.DefaultThreadKey, ParentID: 99, MaxDepth: 2}
+
+	mockService.On("GetCommentsTree", config).Return(([]*model.Comment)(nil), repository.ErrNotSuchComment)
+
+	req := httptest.NewRequest(http.MethodGet, "/comments?parent=99&max_depth=2", nil)
+	w := httptest.NewRecorder()
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	handler.GetComments((*ginext.Context)(c))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_GetComments_InvalidMaxDepth(t *testing.T) {
+	mockService := &MockCommentService{}
+	handler := New(mockService)
+
+	req := httptest.NewRequest(http.MethodGet, "/comments?parent=1&max_depth=-1", nil)
+	w := httptest.NewRecorder()
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	handler.GetComments((*ginext.Context)(c))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "GetCommentsTree")
+}
+
+func TestHandler_GetComments_MaxChildrenRankedSort(t *testing.T) {
+	for _, sort := range []string{"most-replied", "top-voted", "best"} {
+		mockService := &MockCommentService{}
+		handler := New(mockService)
+
+		req := httptest.NewRequest(http.MethodGet, "/comments?parent=1&max_children=2&sort="+sort, nil)
+		w := httptest.NewRecorder()
+
+		c, _ := gin.CreateTestContext(w)
+		c.Request = req
+
+		handler.GetComments((*ginext.Context)(c))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, sort)
+		mockService.AssertNotCalled(t, "GetCommentsTree")
+	}
+}
+
+func TestHandler_GetComments_MaxChildrenChronologicalSort(t *testing.T) {
+	for _, sort := range []dto.SortOrder{"", dto.SortOldest, dto.SortNewest} {
+		mockService := &MockCommentService{}
+		handler := New(mockService)
+
+		config := dto.CommentsPagination{ThreadKey: dto.DefaultThreadKey, ParentID: 1, MaxChildren: 2, Sort: sort}
+		mockService.On("GetCommentsTree", config).Return([]*model.Comment{{ID: 1}}, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/comments?parent=1&max_children=2&sort="+string(sort), nil)
+		w := httptest.NewRecorder()
+
+		c, _ := gin.CreateTestContext(w)
+		c.Request = req
+
+		handler.GetComments((*ginext.Context)(c))
+
+		assert.Equal(t, http.StatusOK, w.Code, sort)
+		mockService.AssertExpectations(t)
+	}
+}
+
+func TestHandler_GetComments_Sorted(t *testing.T) {
+	mockService := &MockCommentService{}
+	handler := New(mockService)
+
+	expected := []*model.Comment{
+		{ID: 1, Text: "Comment 1"},
+	}
+
+	mockService.On("GetCommentsById", dto.CommentsPagination{ThreadKey: dto.DefaultThreadKey, ParentID: 1, Sort: dto.SortMostReplied}).Return(expected, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/comments?parent=1&sort=most-replied", nil)
+	w := httptest.NewRecorder()
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	handler.GetComments((*ginext.Context)(c))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_GetComments_InvalidSort(t *testing.T) {
+	for _, sort := range []string{"random", "TOP"} {
+		mockService := &MockCommentService{}
+		handler := New(mockService)
+
+		req := httptest.NewRequest(http.MethodGet, "/comments?parent=1&sort="+sort, nil)
+		w := httptest.NewRecorder()
+
+		c, _ := gin.CreateTestContext(w)
+		c.Request = req
+
+		handler.GetComments((*ginext.Context)(c))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, sort)
+		mockService.AssertNotCalled(t, "GetCommentsById")
+	}
+}
+
+func TestHandler_GetAllComments_Sorted(t *testing.T) {
+	mockService := &MockCommentService{}
+	handler := New(mockService)
+
+	config := dto.CommentsPagination{ThreadKey: dto.DefaultThreadKey, Sort: dto.SortNewest}
+
+	mockService.On("GetAllComments", config).Return(&model.CommentsPage{Page: 1}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/comments/all?sort=newest", nil)
+	w := httptest.NewRecorder()
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	handler.GetAllComments((*ginext.Context)(c))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_GetComments_ViewerVotes(t *testing.T) {
+	mockService := &MockCommentService{}
+	handler := New(mockService)
+
+	expected := []*model.Comment{
+		{ID: 1, Text: "Comment 1", Votes: model.Votes{Score: 2, Upvotes: 2, MyVote: 1}},
+	}
+	config := dto.CommentsPagination{ThreadKey: dto.DefaultThreadKey, ParentID: 1, Sort: dto.SortBest, ViewerID: "u1"}
+
+	mockService.On("GetCommentsById", config).Return(expected, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/comments?parent=1&sort=best", nil)
+	req.Header.Set("X-User-Id", "u1")
+	w := httptest.NewRecorder()
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	handler.GetComments((*ginext.Context)(c))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response []*model.Comment
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, expected, response)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_VoteComment_Success(t *testing.T) {
+	mockService := &MockCommentService{}
+	handler := New(mockService)
+
+	vote := dto.Vote{Vote: dto.VoteUp}
+	expected := &model.Votes{Score: 3, Upvotes: 4, Downvotes: 1, MyVote: 1}
+
+	mockService.On("Vote", 1, "u1", vote).Return(expected, nil)
+
+	body, _ := json.Marshal(vote)
+	req := httptest.NewRequest(http.MethodPost, "/comments/1/vote", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-User-Id", "u1")
+	w := httptest.NewRecorder()
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+
+	handler.VoteComment((*ginext.Context)(c))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response model.Votes
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, *expected, response)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_VoteComment_Unauthenticated(t *testing.T) {
+	mockService := &MockCommentService{}
+	handler := New(mockService)
+
+	req := httptest.NewRequest(http.MethodPost, "/comments/1/vote", bytes.NewBufferString(`{"vote":"up"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+
+	handler.VoteComment((*ginext.Context)(c))
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	mockService.AssertNotCalled(t, "Vote")
+}
+
+func TestHandler_VoteComment_InvalidVote(t *testing.T) {
+	mockService := &MockCommentService{}
+	handler := New(mockService)
+
+	vote := dto.Vote{Vote: "sideways"}
+
+	mockService.On("Vote", 1, "u1", vote).Return((*model.Votes)(nil), dto.ErrInvalidVote)
+
+	body, _ := json.Marshal(vote)
+	req := httptest.NewRequest(http.MethodPost, "/comments/1/vote", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-User-Id", "u1")
+	w := httptest.NewRecorder()
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+
+	handler.VoteComment((*ginext.Context)(c))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_VoteComment_NotFound(t *testing.T) {
+	mockService := &MockCommentService{}
+	handler := New(mockService)
+
+	vote := dto.Vote{Vote: dto.VoteDown}
+
+	mockService.On("Vote", 99, "u1", vote).Return((*model.Votes)(nil), repository.ErrNotSuchComment)
+
+	body, _ := json.Marshal(vote)
+	req := httptest.NewRequest(http.MethodPost, "/comments/99/vote", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-User-Id", "u1")
+	w := httptest.NewRecorder()
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Params = gin.Params{{Key: "id", Value: "99"}}
+
+	handler.VoteComment((*ginext.Context)(c))
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_AddReaction_Success(t *testing.T) {
+	mockService := &MockCommentService{}
+	handler := New(mockService)
+
+	reaction := dto.Reaction{Emoji: "👍"}
+	expected := []model.Reaction{{Emoji: "👍", Count: 2, Reacted: true}}
+
+	mockService.On("AddReaction", 1, "u1", reaction).Return(expected, nil)
+
+	body, _ := json.Marshal(reaction)
+	req := httptest.NewRequest(http.MethodPost, "/comments/1/reactions", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-User-Id", "u1")
+	w := httptest.NewRecorder()
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+
+	handler.AddReaction((*ginext.Context)(c))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response []model.Reaction
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, expected, response)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_AddReaction_Unauthenticated(t *testing.T) {
+	mockService := &MockCommentService{}
+	handler := New(mockService)
+
+	req := httptest.NewRequest(http.MethodPost, "/comments/1/reactions", bytes.NewBufferString(`{"emoji":"👍"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+
+	handler.AddReaction((*ginext.Context)(c))
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	mockService.AssertNotCalled(t, "AddReaction")
+}
+
+func TestHandler_AddReaction_Invalid(t *testing.T) {
+	mockService := &MockCommentService{}
+	handler := New(mockService)
+
+	reaction := dto.Reaction{Emoji: "thumbs up"}
+
+	mockService.On("AddReaction", 1, "u1", reaction).Return(([]model.Reaction)(nil), dto.ErrInvalidReaction)
+
+	body, _ := json.Marshal(reaction)
+	req := httptest.NewRequest(http.MethodPost, "/comments/1/reactions", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-User-Id", "u1")
+	w := httptest.NewRecorder()
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+
+	handler.AddReaction((*ginext.Context)(c))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_RemoveReaction_Success(t *testing.T) {
+	mockService := &MockCommentService{}
+	handler := New(mockService)
+
+	mockService.On("RemoveReaction", 1, "u1", dto.Reaction{Emoji: "🎉"}).Return([]model.Reaction{}, nil)
+
+	req := httptest.NewRequest(http.MethodDelete, "/comments/1/reactions/%F0%9F%8E%89", nil)
+	req.Header.Set("X-User-Id", "u1")
+	w := httptest.NewRecorder()
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Params = gin.Params{{Key: "id", Value: "1"}, {Key: "emoji", Value: "🎉"}}
+
+	handler.RemoveReaction((*ginext.Context)(c))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, "[]", w.Body.String())
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_RemoveReaction_NotFound(t *testing.T) {
+	mockService := &MockCommentService{}
+	handler := New(mockService)
+
+	mockService.On("RemoveReaction", 99, "u1", dto.Reaction{Emoji: "🎉"}).Return(([]model.Reaction)(nil), repository.ErrNotSuchComment)
+
+	req := httptest.NewRequest(http.MethodDelete, "/comments/99/reactions/%F0%9F%8E%89", nil)
+	req.Header.Set("X-User-Id", "u1")
+	w := httptest.NewRecorder()
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Params = gin.Params{{Key: "id", Value: "99"}, {Key: "emoji", Value: "🎉"}}
+
+	handler.RemoveReaction((*ginext.Context)(c))
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_GetCommentById_Success(t *testing.T) {
+	mockService := &MockCommentService{}
+	handler := New(mockService)
+
+	parentID := 1
+	expected := &model.Comment{ID: 2, ParentID: &parentID, Text: "reply", CreatedAt: time.Now().UTC()}
+
+	mockService.On("GetCommentById", 2, "u1").Return(expected, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/comments/2", nil)
+	req.Header.Set("X-User-Id", "u1")
+	w := httptest.NewRecorder()
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Params = gin.Params{{Key: "id", Value: "2"}}
+
+	handler.GetCommentById((*ginext.Context)(c))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response model.Comment
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, expected.ID, response.ID)
+	assert.Equal(t, expected.Text, response.Text)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_GetCommentById_NotFound(t *testing.T) {
+	mockService := &MockCommentService{}
+	handler := New(mockService)
+
+	mockService.On("GetCommentById", 99, "").Return((*model.Comment)(nil), repository.ErrNotSuchComment)
+
+	req := httptest.NewRequest(http.MethodGet, "/comments/99", nil)
+	w := httptest.NewRecorder()
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Params = gin.Params{{Key: "id", Value: "99"}}
+
+	handler.GetCommentById((*ginext.Context)(c))
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_GetCommentById_InvalidID(t *testing.T) {
+	mockService := &MockCommentService{}
+	handler := New(mockService)
+
+	req := httptest.NewRequest(http.MethodGet, "/comments/abc", nil)
+	w := httptest.NewRecorder()
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Params = gin.Params{{Key: "id", Value: "abc"}}
+
+	handler.GetCommentById((*ginext.Context)(c))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "GetCommentById")
+}
+
+func TestHandler_GetCommentAncestors_Success(t *testing.T) {
+	mockService := &MockCommentService{}
+	handler := New(mockService)
+
+	rootID := 1
+	expected := []model.Comment{
+		{ID: 1, Text: "root"},
+		{ID: 2, ParentID: &rootID, Text: "reply"},
+	}
+
+	mockService.On("GetCommentAncestors", 3, "").Return(expected, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/comments/3/ancestors", nil)
+	w := httptest.NewRecorder()
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Params = gin.Params{{Key: "id", Value: "3"}}
+
+	handler.GetCommentAncestors((*ginext.Context)(c))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response []model.Comment
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Len(t, response, 2)
+	assert.Equal(t, 1, response[0].ID)
+	assert.Equal(t, 2, response[1].ID)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_GetCommentAncestors_Root(t *testing.T) {
+	mockService := &MockCommentService{}
+	handler := New(mockService)
+
+	mockService.On("GetCommentAncestors", 1, "").Return([]model.Comment{}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/comments/1/ancestors", nil)
+	w := httptest.NewRecorder()
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+
+	handler.GetCommentAncestors((*ginext.Context)(c))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, "[]", w.Body.String())
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_GetCommentAncestors_NotFound(t *testing.T) {
+	mockService := &MockCommentService{}
+	handler := New(mockService)
+
+	mockService.On("GetCommentAncestors", 99, "").Return(([]model.Comment)(nil), repository.ErrNotSuchComment)
+
+	req := httptest.NewRequest(http.MethodGet, "/comments/99/ancestors", nil)
+	w := httptest.NewRecorder()
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Params = gin.Params{{Key: "id", Value: "99"}}
+
+	handler.GetCommentAncestors((*ginext.Context)(c))
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_GetCommentContext_Success(t *testing.T) {
+	mockService := &MockCommentService{}
+	handler := New(mockService)
+
+	rootID := 1
+	focus := &model.Comment{ID: 2, ParentID: &rootID, Text: "reply"}
+	expected := []*model.Comment{{ID: 1, Text: "root", Children: []*model.Comment{focus}}}
+
+	config := dto.CommentContext{ID: 2, Up: 2, Down: 0, Sort: dto.SortNewest, ViewerID: "u1"}
+	mockService.On("GetCommentContext", config).Return(expected, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/comments/2/context?up=2&down=0&sort=newest", nil)
+	req.Header.Set("X-User-Id", "u1")
+	w := httptest.NewRecorder()
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Params = gin.Params{{Key: "id", Value: "2"}}
+
+	handler.GetCommentContext((*ginext.Context)(c))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response []*model.Comment
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Len(t, response, 1)
+	assert.Equal(t, 2, response[0].Children[0].ID)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_GetCommentContext_Defaults(t *testing.T) {
+	mockService := &MockCommentService{}
+	handler := New(mockService)
+
+	config := dto.CommentContext{ID: 2, Up: dto.DefaultContextUp, Down: dto.DefaultContextDown}
+	mockService.On("GetCommentContext", config).Return([]*model.Comment{{ID: 2}}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/comments/2/context", nil)
+	w := httptest.NewRecorder()
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Params = gin.Params{{Key: "id", Value: "2"}}
+
+	handler.GetCommentContext((*ginext.Context)(c))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_GetCommentContext_InvalidParameters(t *testing.T) {
+	for _, query := range []string{"up=-1", "down=abc", "sort=random"} {
+		mockService := &MockCommentService{}
+		handler := New(mockService)
+
+		req := httptest.NewRequest(http.MethodGet, "/comments/2/context?"+query, nil)
+		w := httptest.NewRecorder()
+
+		c, _ := gin.CreateTestContext(w)
+		c.Request = req
+		c.Params = gin.Params{{Key: "id", Value: "2"}}
+
+		handler.GetCommentContext((*ginext.Context)(c))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+		mockService.AssertNotCalled(t, "GetCommentContext")
+	}
+}
+
+func TestHandler_GetCommentContext_NotFound(t *testing.T) {
+	mockService := &MockCommentService{}
+	handler := New(mockService)
+
+	config := dto.CommentContext{ID: 99, Up: dto.DefaultContextUp, Down: dto.DefaultContextDown}
+	mockService.On("GetCommentContext", config).Return(([]*model.Comment)(nil), repository.ErrNotSuchComment)
+
+	req := httptest.NewRequest(http.MethodGet, "/comments/99/context", nil)
+	w := httptest.NewRecorder()
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Params = gin.Params{{Key: "id", Value: "99"}}
+
+	handler.GetCommentContext((*ginext.Context)(c))
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_MoveComment_Success(t *testing.T) {
+	mockService := &MockCommentService{}
+	handler := New(mockService)
+
+	parentID := 7
+	move := dto.MoveComment{ParentID: &parentID}
+	requester := dto.Requester{ID: "mod", Admin: true}
+	expected := []*model.Comment{{ID: 3, ParentID: &parentID, Text: "moved"}}
+
+	mockService.On("MoveComment", 3, requester, move).Return(expected, nil)
+
+	body, _ := json.Marshal(move)
+	req := httptest.NewRequest(http.MethodPost, "/comments/3/move", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-User-Id", "mod")
+	req.Header.Set("X-User-Role", "admin")
+	w := httptest.NewRecorder()
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Params = gin.Params{{Key: "id", Value: "3"}}
+
+	handler.MoveComment((*ginext.Context)(c))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response []*model.Comment
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Len(t, response, 1)
+	assert.Equal(t, &parentID, response[0].ParentID)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_MoveComment_ToRoot(t *testing.T) {
+	mockService := &MockCommentService{}
+	handler := New(mockService)
+
+	requester := dto.Requester{ID: "mod", Admin: true}
+	mockService.On("MoveComment", 3, requester, dto.MoveComment{}).Return([]*model.Comment{{ID: 3}}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/comments/3/move", bytes.NewBufferString(`{"parent_id": null}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-User-Id", "mod")
+	req.Header.Set("X-User-Role", "admin")
+	w := httptest.NewRecorder()
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Params = gin.Params{{Key: "id", Value: "3"}}
+
+	handler.MoveComment((*ginext.Context)(c))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_MoveComment_NotAdmin(t *testing.T) {
+	mockService := &MockCommentService{}
+	handler := New(mockService)
+
+	req := httptest.NewRequest(http.MethodPost, "/comments/3/move", bytes.NewBufferString(`{"parent_id": 7}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-User-Id", "u1")
+	w := httptest.NewRecorder()
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+	c.Params = gin.Params{{Key: "id", Value: "3"}}
+
+	handler.MoveComment((*ginext.Context)(c))
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	mockService.AssertNotCalled(t, "MoveComment")
+}
+
+func TestHandler_MoveComment_SpoofedRole(t *testing.T) {
+	tests := []struct {
+		name       string
+		headers    map[string]string
+		wantStatus int
+	}{
+		{
+			name:       "role from client",
+			headers:    map[string]string{"X-User-Id": "mallory", "X-User-Role": "admin"},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "role with wrong secret",
+			headers:    map[string]string{"X-User-Id": "mallory", "X-User-Role": "admin", "X-Proxy-Secret": "guess"},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "proxy user without role",
+			headers:    map[string]string{"X-User-Id": "u1", "X-Proxy-Secret": "s3cret"},
+			wantStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockCommentService{}
+			handler := New(mockService)
+
+			w := httptest.NewRecorder()
+			_, engine := gin.CreateTestContext(w)
+			engine.Use(TrustedProxy("s3cret"))
+			engine.POST("/comments/:id/move", handler.MoveComment)
+
+			req := httptest.NewRequest(http.MethodPost, "/comments/3/move", bytes.NewBufferString(`{"parent_id": 7}`))
+			req.Header.Set("Content-Type", "application/json")
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+
+			engine.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			mockService.AssertNotCalled(t, "MoveComment")
+		})
+	}
+}
+
+func TestHandler_MoveComment_Errors(t *testing.T) {
+	tests := []struct {
+		err  error
+		code int
+	}{
+		{repository.ErrMoveIntoSubtree, http.StatusBadRequest},
+		{repository.ErrInvalidParenID, http.StatusBadRequest},
+		{repository.ErrNotSuchComment, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		mockService := &MockCommentService{}
+		handler := New(mockService)
+
+		parentID := 4
+		requester := dto.Requester{ID: "mod", Admin: true}
+		mockService.On("MoveComment", 3, requester, dto.MoveComment{ParentID: &parentID}).Return(([]*model.Comment)(nil), tt.err)
+
+		req := httptest.NewRequest(http.MethodPost, "/comments/3/move", bytes.NewBufferString(`{"parent_id": 4}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-User-Id", "mod")
+		req.Header.Set("X-User-Role", "admin")
+		w := httptest.NewRecorder()
+
+		c, _ := gin.CreateTestContext(w)
+		c.Request = req
+		c.Params = gin.Params{{Key: "id", Value: "3"}}
+
+		handler.MoveComment((*ginext.Context)(c))
+
+		assert.Equal(t, tt.code, w.Code, tt.err.Error())
+		mockService.AssertExpectations(t)
+	}
+}
+
+func TestHandler_GetCommentsByTextSearch_Highlight(t *testing.T) {
+	mockService := &MockCommentService{}
+	handler := New(mockService)
+
+	searchText := dto.SearchText{
+		ThreadKey: dto.DefaultThreadKey,
+		Text:      "search",
+		Highlight: &dto.Highlight{StartSel: "<mark>", StopSel: "</mark>", MaxFragments: 2},
+	}
+	rank := 0.5
+	snippet := "comment with <mark>search</mark>"
+	total := 1
+	expected := &model.CommentsPage{
+		Items:      []*model.Comment{{ID: 1, Text: "Comment with search", Rank: &rank, Snippet: &snippet}},
+		TotalCount: &total,
+	}
+
+	mockService.On("GetCommentsByTextSearch", searchText).Return(expected, nil)
+
+	body, _ := json.Marshal(searchText)
+	req := httptest.NewRequest(http.MethodPost, "/comments/search", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	handler.GetCommentsByTextSearch((*ginext.Context)(c))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response model.CommentsPage
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, expected, &response)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_GetCommentsByTextSearch_InvalidHighlight(t *testing.T) {
+	mockService := &MockCommentService{}
+	handler := New(mockService)
+
+	searchText := dto.SearchText{
+		ThreadKey: dto.DefaultThreadKey,
+		Text:      "search",
+		Highlight: &dto.Highlight{MaxFragments: -1},
+	}
+
+	mockService.On("GetCommentsByTextSearch", searchText).Return((*model.CommentsPage)(nil), dto.ErrInvalidHighlight)
+
+	body, _ := json.Marshal(searchText)
+	req := httptest.NewRequest(http.MethodPost, "/comments/search", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	handler.GetCommentsByTextSearch((*ginext.Context)(c))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_CreateComment_UnsupportedLanguage(t *testing.T) {
+	mockService := &MockCommentService{}
+	handler := New(mockService)
+
+	author := &model.Author{ID: "u1", DisplayName: "u1"}
+	comment := dto.CreateComment{ThreadKey: dto.DefaultThreadKey, Author: author, Text: "Bonjour", Language: "fr"}
+
+	mockService.On("CreateComment", comment).Return((*dto.CreateComment)(nil), dto.ErrInvalidLanguage)
+
+	req := httptest.NewRequest(http.MethodPost, "/comments", bytes.NewBufferString(`{"text": "Bonjour", "language": "fr"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-User-Id", "u1")
+	w := httptest.NewRecorder()
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	handler.CreateComment((*ginext.Context)(c))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_GetCommentsByTextSearch_Language(t *testing.T) {
+	mockService := &MockCommentService{}
+	handler := New(mockService)
+
+	searchText := dto.SearchText{ThreadKey: dto.DefaultThreadKey, Text: "search", Lang: "en"}
+	expected := &model.CommentsPage{Items: []*model.Comment{{ID: 1, Language: "en"}}}
+
+	mockService.On("GetCommentsByTextSearch", searchText).Return(expected, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/comments/search?lang=en", bytes.NewBufferString(`{"text": "search"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	handler.GetCommentsByTextSearch((*ginext.Context)(c))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_GetCommentsByTextSearch_UnsupportedLanguage(t *testing.T) {
+	mockService := &MockCommentService{}
+	handler := New(mockService)
+
+	searchText := dto.SearchText{ThreadKey: dto.DefaultThreadKey, Text: "search", Lang: "fr"}
+
+	mockService.On("GetCommentsByTextSearch", searchText).Return((*model.CommentsPage)(nil), dto.ErrInvalidLanguage)
+
+	req := httptest.NewRequest(http.MethodPost, "/comments/search?lang=fr", bytes.NewBufferString(`{"text": "search"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	handler.GetCommentsByTextSearch((*ginext.Context)(c))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_GetCommentsByTextSearch_Filters(t *testing.T) {
+	mockService := &MockCommentService{}
+	handler := New(mockService)
+
+	from := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+	minScore := 3
+	searchText := dto.SearchText{
+		ThreadKey: dto.DefaultThreadKey,
+		Text:      `"дерево комментариев" or поиск`,
+		From:      &from,
+		AuthorID:  "alice",
+		MinScore:  &minScore,
+		RootsOnly: true,
+		Sort:      dto.SortNewest,
+	}
+	expected := &model.CommentsPage{Items: []*model.Comment{{ID: 1}}}
+
+	mockService.On("GetCommentsByTextSearch", searchText).Return(expected, nil)
+
+	body := `{"text": "\"дерево комментариев\" or поиск", "from": "2025-10-01T00:00:00Z", "author_id": "alice", "min_score": 3, "roots_only": true}`
+	req := httptest.NewRequest(http.MethodPost, "/comments/search?sort=newest", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	handler.GetCommentsByTextSearch((*ginext.Context)(c))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_GetCommentsByTextSearch_InvalidFilter(t *testing.T) {
+	mockService := &MockCommentService{}
+	handler := New(mockService)
+
+	mockService.On("GetCommentsByTextSearch", mock.Anything).Return((*model.CommentsPage)(nil), dto.ErrInvalidSearchFilter)
+
+	body := `{"text": "search", "from": "2025-10-02T00:00:00Z", "to": "2025-10-01T00:00:00Z"}`
+	req := httptest.NewRequest(http.MethodPost, "/comments/search", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	handler.GetCommentsByTextSearch((*ginext.Context)(c))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_GetCommentsByTextSearch_Fuzzy(t *testing.T) {
+	mockService := &MockCommentService{}
+	handler := New(mockService)
+
+	similarity := 0.4
+	searchText := dto.SearchText{
+		ThreadKey:  dto.DefaultThreadKey,
+		Text:       "коментарий",
+		Mode:       dto.SearchBoth,
+		Similarity: &similarity,
+	}
+	expected := &model.CommentsPage{Items: []*model.Comment{{ID: 1}}}
+
+	mockService.On("GetCommentsByTextSearch", searchText).Return(expected, nil)
+
+	body := `{"text": "коментарий", "mode": "both", "similarity": 0.4}`
+	req := httptest.NewRequest(http.MethodPost, "/comments/search", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	handler.GetCommentsByTextSearch((*ginext.Context)(c))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_SuggestComments(t *testing.T) {
+	mockService := &MockCommentService{}
+	handler := New(mockService)
+
+	suggest := dto.Suggest{ThreadKey: "article-42", Query: "дер", Limit: 3, Lang: "ru"}
+	expected := &model.Suggestions{
+		Comments: []model.Suggestion{{ID: 1, Excerpt: "<b>дерево</b> комментариев"}},
+		Terms:    []string{"дерев"},
+	}
+
+	mockService.On("SuggestComments", suggest).Return(expected, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/comments/suggest?q=%D0%B4%D0%B5%D1%80&thread=article-42&limit=3&lang=ru", nil)
+	w := httptest.NewRecorder()
+
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	handler.SuggestComments((*ginext.Context)(c))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response model.Suggestions
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, *expected, response)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_SuggestComments_InvalidLimit(t *testing.T) {
+	for _, limit := range []string{"0", "11", "abc"} {
+		mockService := &MockCommentService{}
+		handler := New(mockService)
+
+		req := httptest.NewRequest(http.MethodGet, "/comments/suggest?q=test&limit="+limit, nil)
+		w := httptest.NewRecorder()
+
+		c, _ := gin.CreateTestContext(w)
+		c.Request = req
+
+		handler.SuggestComments((*ginext.Context)(c))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, limit)
+		mockService.AssertNotCalled(t, "SuggestComments")
+	}
+}
+
+func TestRequestTimeout(t *testing.T) {
+	w := httptest.NewRecorder()
+	_, engine := gin.CreateTestContext(w)
+
+	var deadline time.Time
+	var hasDeadline bool
+	engine.Use(RequestTimeout(2 * time.Second))
+	engine.GET("/comments", func(c *gin.Context) {
+		deadline, hasDeadline = c.Request.Context().Deadline()
+	})
+
+	start := time.Now()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/comments", nil))
+
+	assert.True(t, hasDeadline)
+	assert.WithinDuration(t, start.Add(2*time.Second), deadline, time.Second)
+}
+
+func TestTrustedProxy_SpoofedIdentity(t *testing.T) {
+	tests := []struct {
+		name         string
+		proxySecret  string
+		headerSecret string
+	}{
+		{name: "no secret configured", proxySecret: "", headerSecret: ""},
+		{name: "no secret configured, secret sent", proxySecret: "", headerSecret: "guess"},
+		{name: "secret missing", proxySecret: "s3cret", headerSecret: ""},
+		{name: "wrong secret", proxySecret: "s3cret", headerSecret: "guess"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockCommentService{}
+			handler := New(mockService)
+
+			w := httptest.NewRecorder()
+			_, engine := gin.CreateTestContext(w)
+			engine.Use(TrustedProxy(tt.proxySecret))
+			engine.POST("/comments", handler.CreateComment)
+
+			body, _ := json.Marshal(dto.CreateComment{Text: "Test comment"})
+			req := httptest.NewRequest(http.MethodPost, "/comments", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-User-Id", "alice")
+			req.Header.Set("X-User-Name", "Alice")
+			if tt.headerSecret != "" {
+				req.Header.Set("X-Proxy-Secret", tt.headerSecret)
+			}
+
+			engine.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+			mockService.AssertNotCalled(t, "CreateComment")
+		})
+	}
+}
+
+func TestTrustedProxy_TrustedIdentity(t *testing.T) {
+	mockService := &MockCommentService{}
+	handler := New(mockService)
+
+	comment := dto.CreateComment{
+		ThreadKey: dto.DefaultThreadKey,
+		Author:    &model.Author{ID: "alice", DisplayName: "Alice"},
+		Text:      "Test comment",
+	}
+	mockService.On("CreateComment", comment).Return(&comment, nil)
+
+	w := httptest.NewRecorder()
+	_, engine := gin.CreateTestContext(w)
+	engine.Use(TrustedProxy("s3cret"))
+	var forwardedSecret string
+	engine.POST("/comments", func(c *gin.Context) {
+		forwardedSecret = c.GetHeader("X-Proxy-Secret")
+		handler.CreateComment(c)
+	})
+
+	body, _ := json.Marshal(dto.CreateComment{Text: "Test comment"})
+	req := httptest.NewRequest(http.MethodPost, "/comments", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-User-Id", "alice")
+	req.Header.Set("X-User-Name", "Alice")
+	req.Header.Set("X-Proxy-Secret", "s3cret")
+
+	engine.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, forwardedSecret)
+	mockService.AssertExpectations(t)
+}
+
+func TestHealth_Healthz(t *testing.T) {
+	health := NewHealth(&MockCommentService{}, 20251013090000, model.BuildInfo{})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/healthz", nil)
+
+	health.Healthz((*ginext.Context)(c))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestHealth_Readyz(t *testing.T) {
+	tests := []struct {
+		name       string
+		version    int64
+		err        error
+		wantStatus int
+		want       model.Readiness
+	}{
+		{
+			name:       "ready",
+			version:    20251013090000,
+			wantStatus: http.StatusOK,
+			want:       model.Readiness{Status: "ready", Migration: 20251013090000, ExpectedMigration: 20251013090000},
+		},
+		{
+			name:       "migrations behind",
+			version:    20251012090000,
+			err:        errors.New("database migrations are behind"),
+			wantStatus: http.StatusServiceUnavailable,
+			want: model.Readiness{
+				Status:            "not ready",
+				Migration:         20251012090000,
+				ExpectedMigration: 20251013090000,
+				Error:             "database migrations are behind",
+			},
+		},
+		{
+			name:       "database unreachable",
+			err:        errors.New("could not reach db"),
+			wantStatus: http.StatusServiceUnavailable,
+			want:       model.Readiness{Status: "not ready", ExpectedMigration: 20251013090000, Error: "could not reach db"},
+		},
+	}
+
+	for _, tt := range tests {
+		mockService := &MockCommentService{}
+		health := NewHealth(mockService, 20251013090000, model.BuildInfo{})
+
+		mockService.On("CheckReadiness", int64(20251013090000)).Return(tt.version, tt.err)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/readyz", nil)
+
+		health.Readyz((*ginext.Context)(c))
+
+		assert.Equal(t, tt.wantStatus, w.Code, tt.name)
+
+		var response model.Readiness
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err, tt.name)
+		assert.Equal(t, tt.want, response, tt.name)
+		mockService.AssertExpectations(t)
+	}
+}
+
+func TestHealth_Version(t *testing.T) {
+	build := model.BuildInfo{Version: "1.2.0", Commit: "0f4508b", GoVersion: "go1.23.0"}
+	health := NewHealth(&MockCommentService{}, 0, build)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/version", nil)
+
+	health.Version((*ginext.Context)(c))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response model.BuildInfo
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, build, response)
+}
@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Komilov31/comment-tree/internal/model"
+	"github.com/wb-go/wbf/ginext"
+	"github.com/wb-go/wbf/zlog"
+)
+
+type ReadinessChecker interface {
+	CheckReadiness(context.Context, int64) (int64, error)
+}
+
+// Health serves the probes of the orchestrator and the build information.
+type Health struct {
+	checker           ReadinessChecker
+	expectedMigration int64
+	build             model.BuildInfo
+}
+
+func NewHealth(checker ReadinessChecker, expectedMigration int64, build model.BuildInfo) *Health {
+	return &Health{
+		checker:           checker,
+		expectedMigration: expectedMigration,
+		build:             build,
+	}
+}
+
+// @Summary Проверка жизнеспособности
+// @Description Отвечает, пока процесс жив, не обращаясь к базе данных
+// @Tags health
+// @Produce json
+// @Success 200 {object} map[string]string "status":"ok"
+// @Router /healthz [get]
+func (h *Health) Healthz(c *ginext.Context) {
+	c.JSON(http.StatusOK, ginext.H{
+		"status": "ok",
+	})
+}
+
+// @Summary Проверка готовности
+// @Description Проверяет, что база данных доступна и к ней применены все миграции, поставляемые с сервисом. Пока сервис не готов, на него не стоит направлять запросы
+// @Tags health
+// @Produce json
+// @Success 200 {object} model.Readiness "Сервис готов"
+// @Failure 503 {object} model.Readiness "База данных недоступна или миграции не применены"
+// @Router /readyz [get]
+func (h *Health) Readyz(c *ginext.Context) {
+	readiness := model.Readiness{
+		Status:            "ready",
+		ExpectedMigration: h.expectedMigration,
+	}
+
+	version, err := h.checker.CheckReadiness(c.Request.Context(), h.expectedMigration)
+	readiness.Migration = version
+	if err != nil {
+		zlog.Logger.Error().Msgf("service is not ready: %s", err.Error())
+		readiness.Status = "not ready"
+		readiness.Error = err.Error()
+		c.JSON(http.StatusServiceUnavailable, readiness)
+		return
+	}
+
+	c.JSON(http.StatusOK, readiness)
+}
+
+// @Summary Версия сервиса
+// @Description Возвращает версию сборки, коммит, из которого она собрана, и версию Go
+// @Tags health
+// @Produce json
+// @Success 200 {object} model.BuildInfo "Информация о сборке"
+// @Router /version [get]
+func (h *Health) Version(c *ginext.Context) {
+	c.JSON(http.StatusOK, h.build)
+}
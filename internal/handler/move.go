@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Komilov31/comment-tree/internal/dto"
+	_ "github.com/Komilov31/comment-tree/internal/model"
+	"github.com/Komilov31/comment-tree/internal/repository"
+	"github.com/wb-go/wbf/ginext"
+	"github.com/wb-go/wbf/zlog"
+)
+
+// @Summary Перенести комментарий (администратор)
+// @Description Переносит комментарий вместе со всеми ответами под другой комментарий или делает его корневым (parent_id: null). Нельзя перенести комментарий под самого себя или под один из его ответов. Ответы всегда находятся в треде родителя, поэтому при переносе в другой тред поддерево переходит вместе с ним. Доступно только администраторам
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path int true "ID переносимого комментария"
+// @Param X-User-Id header string true "ID пользователя"
+// @Param X-User-Role header string true "Роль пользователя (admin)"
+// @Param move body dto.MoveComment true "Новый родительский комментарий"
+// @Success 200 {array} model.Comment "Перенесенное поддерево"
+// @Failure 400 {object} map[string]string "error":"invalid payload", "there is not parent comment with provided id" or "comment cannot be moved under itself or its replies"
+// @Failure 401 {object} map[string]string "error":"authentication required"
+// @Failure 403 {object} map[string]string "error":"admin role required"
+// @Failure 404 {object} map[string]string "error":"there is not comment with such id"
+// @Failure 500 {object} map[string]string "error":"could not move comment"
+// @Router /comments/{id}/move [post]
+func (h *Handler) MoveComment(c *ginext.Context) {
+	commentId, err := commentIDParam(c)
+	if err != nil {
+		zlog.Logger.Error().Msgf("invalid id was provided: %s", err.Error())
+		c.JSON(http.StatusBadRequest, ginext.H{
+			"error": "invalid id was provided",
+		})
+		return
+	}
+
+	requester, err := currentRequester(c)
+	if err != nil {
+		zlog.Logger.Error().Msgf("could not identify requester: %s", err.Error())
+		c.JSON(http.StatusUnauthorized, ginext.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if !requester.Admin {
+		zlog.Logger.Error().Msgf("user %s tried to move comment %d", requester.ID, commentId)
+		c.JSON(http.StatusForbidden, ginext.H{
+			"error": errAdminRequired.Error(),
+		})
+		return
+	}
+
+	var move dto.MoveComment
+	if err := c.BindJSON(&move); err != nil {
+		zlog.Logger.Error().Msgf("could not unmarshal request body to model: %s", err.Error())
+		c.JSON(http.StatusBadRequest, ginext.H{
+			"error": "invalid payload: " + err.Error(),
+		})
+		return
+	}
+
+	comments, err := h.service.MoveComment(c.Request.Context(), commentId, requester, move)
+	if err != nil {
+		zlog.Logger.Error().Msgf("could not move comment: %s", err.Error())
+		switch {
+		case errors.Is(err, repository.ErrNotSuchComment):
+			c.JSON(http.StatusNotFound, ginext.H{
+				"error": err.Error(),
+			})
+		case errors.Is(err, repository.ErrInvalidParenID), errors.Is(err, repository.ErrMoveIntoSubtree):
+			c.JSON(http.StatusBadRequest, ginext.H{
+				"error": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, ginext.H{
+				"error": "could not move comment: " + err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, comments)
+}
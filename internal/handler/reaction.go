@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Komilov31/comment-tree/internal/dto"
+	_ "github.com/Komilov31/comment-tree/internal/model"
+	"github.com/Komilov31/comment-tree/internal/repository"
+	"github.com/wb-go/wbf/ginext"
+	"github.com/wb-go/wbf/zlog"
+)
+
+// @Summary Добавить реакцию
+// @Description Добавляет реакцию-эмодзи текущего пользователя к комментарию. Повторная реакция тем же эмодзи ничего не меняет
+// @Tags reactions
+// @Accept json
+// @Produce json
+// @Param id path int true "ID комментария"
+// @Param X-User-Id header string true "ID пользователя"
+// @Param reaction body dto.Reaction true "Эмодзи реакции"
+// @Success 200 {array} model.Reaction "Реакции комментария"
+// @Failure 400 {object} map[string]string "error":"invalid payload" or "invalid reaction"
+// @Failure 401 {object} map[string]string "error":"authentication required"
+// @Failure 404 {object} map[string]string "error":"there is not comment with such id"
+// @Failure 500 {object} map[string]string "error":"could not change reaction"
+// @Router /comments/{id}/reactions [post]
+func (h *Handler) AddReaction(c *ginext.Context) {
+	commentId, userID, ok := reactionRequest(c)
+	if !ok {
+		return
+	}
+
+	var reaction dto.Reaction
+	if err := c.BindJSON(&reaction); err != nil {
+		zlog.Logger.Error().Msgf("could not unmarshal request body to model: %s", err.Error())
+		c.JSON(http.StatusBadRequest, ginext.H{
+			"error": "invalid payload: " + err.Error(),
+		})
+		return
+	}
+
+	reactions, err := h.service.AddReaction(c.Request.Context(), commentId, userID, reaction)
+	if err != nil {
+		writeReactionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, reactions)
+}
+
+// @Summary Убрать реакцию
+// @Description Убирает реакцию-эмодзи текущего пользователя с комментария
+// @Tags reactions
+// @Accept json
+// @Produce json
+// @Param id path int true "ID комментария"
+// @Param emoji path string true "Эмодзи реакции (URL-encoded)"
+// @Param X-User-Id header string true "ID пользователя"
+// @Success 200 {array} model.Reaction "Оставшиеся реакции комментария"
+// @Failure 400 {object} map[string]string "error":"invalid id was provided" or "invalid reaction"
+// @Failure 401 {object} map[string]string "error":"authentication required"
+// @Failure 404 {object} map[string]string "error":"there is not comment with such id"
+// @Failure 500 {object} map[string]string "error":"could not change reaction"
+// @Router /comments/{id}/reactions/{emoji} [delete]
+func (h *Handler) RemoveReaction(c *ginext.Context) {
+	commentId, userID, ok := reactionRequest(c)
+	if !ok {
+		return
+	}
+
+	reactions, err := h.service.RemoveReaction(c.Request.Context(), commentId, userID, dto.Reaction{Emoji: c.Param("emoji")})
+	if err != nil {
+		writeReactionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, reactions)
+}
+
+// reactionRequest reads the comment id and the caller of a reaction
+// request, writing the error response when either is missing.
+func reactionRequest(c *ginext.Context) (int, string, bool) {
+	commentId, err := commentIDParam(c)
+	if err != nil {
+		zlog.Logger.Error().Msgf("invalid id was provided: %s", err.Error())
+		c.JSON(http.StatusBadRequest, ginext.H{
+			"error": "invalid id was provided",
+		})
+		return 0, "", false
+	}
+
+	user, err := currentUser(c)
+	if err != nil {
+		zlog.Logger.Error().Msgf("could not identify user: %s", err.Error())
+		c.JSON(http.StatusUnauthorized, ginext.H{
+			"error": err.Error(),
+		})
+		return 0, "", false
+	}
+
+	return commentId, user.ID, true
+}
+
+func writeReactionError(c *ginext.Context, err error) {
+	zlog.Logger.Error().Msgf("could not change reaction: %s", err.Error())
+	switch {
+	case errors.Is(err, dto.ErrInvalidReaction):
+		c.JSON(http.StatusBadRequest, ginext.H{
+			"error": err.Error(),
+		})
+	case errors.Is(err, repository.ErrNotSuchComment):
+		c.JSON(http.StatusNotFound, ginext.H{
+			"error": err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, ginext.H{
+			"error": "could not change reaction: " + err.Error(),
+		})
+	}
+}
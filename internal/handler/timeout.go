@@ -0,0 +1,27 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/wb-go/wbf/ginext"
+)
+
+// RequestTimeout gives every request a deadline of timeout. The handlers
+// pass the request context down to the database, so queries are cancelled
+// when the deadline passes or the client disconnects. A zero timeout only
+// cancels on disconnect.
+func RequestTimeout(timeout time.Duration) ginext.HandlerFunc {
+	return func(c *ginext.Context) {
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
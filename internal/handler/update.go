@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Komilov31/comment-tree/internal/dto"
+	_ "github.com/Komilov31/comment-tree/internal/model"
+	"github.com/Komilov31/comment-tree/internal/repository"
+	"github.com/wb-go/wbf/ginext"
+	"github.com/wb-go/wbf/zlog"
+)
+
+// @Summary Редактировать комментарий
+// @Description Изменяет текст комментария. Предыдущая версия сохраняется в истории правок
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param id path int true "ID комментария"
+// @Param X-User-Id header string true "ID автора"
+// @Param comment body dto.UpdateComment true "Новый текст комментария"
+// @Success 200 {object} model.Comment "Обновленный комментарий"
+// @Failure 400 {object} map[string]string "error":"invalid payload" or "invalid id was provided"
+// @Failure 401 {object} map[string]string "error":"authentication required"
+// @Failure 403 {object} map[string]string "error":"comment belongs to another author"
+// @Failure 404 {object} map[string]string "error":"there is not comment with such id"
+// @Failure 500 {object} map[string]string "error":"could not update comment"
+// @Router /comments/{id} [patch]
+func (h *Handler) UpdateComment(c *ginext.Context) {
+	commentId, err := commentIDParam(c)
+	if err != nil {
+		zlog.Logger.Error().Msgf("invalid id was provided: %s", err.Error())
+		c.JSON(http.StatusBadRequest, ginext.H{
+			"error": "invalid id was provided",
+		})
+		return
+	}
+
+	author, err := currentUser(c)
+	if err != nil {
+		zlog.Logger.Error().Msgf("could not identify comment author: %s", err.Error())
+		c.JSON(http.StatusUnauthorized, ginext.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	var update dto.UpdateComment
+	if err := c.BindJSON(&update); err != nil {
+		zlog.Logger.Error().Msgf("could not unmarshal request body to model: %s", err.Error())
+		c.JSON(http.StatusBadRequest, ginext.H{
+			"error": "invalid payload: " + err.Error(),
+		})
+		return
+	}
+
+	comment, err := h.service.UpdateComment(c.Request.Context(), commentId, author.ID, update)
+	if err != nil {
+		zlog.Logger.Error().Msgf("could not update comment: %s", err.Error())
+		switch {
+		case errors.Is(err, repository.ErrNotSuchComment):
+			c.JSON(http.StatusNotFound, ginext.H{
+				"error": err.Error(),
+			})
+		case errors.Is(err, repository.ErrNotAuthor):
+			c.JSON(http.StatusForbidden, ginext.H{
+				"error": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, ginext.H{
+				"error": "could not update comment: " + err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, comment)
+}
+
+// @Summary История правок комментария
+// @Description Возвращает все версии комментария от первой до текущей вместе с отличиями от предыдущей версии
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param id path int true "ID комментария"
+// @Success 200 {array} model.Revision "Версии комментария"
+// @Failure 400 {object} map[string]string "error":"invalid id was provided"
+// @Failure 404 {object} map[string]string "error":"there is not comment with such id"
+// @Failure 500 {object} map[string]string "error":"could not get revisions"
+// @Router /comments/{id}/revisions [get]
+func (h *Handler) GetCommentRevisions(c *ginext.Context) {
+	commentId, err := commentIDParam(c)
+	if err != nil {
+		zlog.Logger.Error().Msgf("invalid id was provided: %s", err.Error())
+		c.JSON(http.StatusBadRequest, ginext.H{
+			"error": "invalid id was provided",
+		})
+		return
+	}
+
+	revisions, err := h.service.GetCommentRevisions(c.Request.Context(), commentId)
+	if err != nil {
+		zlog.Logger.Error().Msgf("could not get comment revisions: %s", err.Error())
+		if errors.Is(err, repository.ErrNotSuchComment) {
+			c.JSON(http.StatusNotFound, ginext.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, ginext.H{
+			"error": "could not get revisions: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, revisions)
+}
@@ -6,17 +6,47 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 
 	"github.com/Komilov31/comment-tree/internal/dto"
+	"github.com/Komilov31/comment-tree/internal/model"
 	"github.com/Komilov31/comment-tree/internal/repository"
 	"github.com/wb-go/wbf/ginext"
 	"github.com/wb-go/wbf/zlog"
 )
 
 func parserQueryParameters(params url.Values) (*dto.CommentsPagination, error) {
-	var id, page, limit int
+	var id, page, limit, maxDepth, maxChildren int
+	var threadKey string
+	var sort dto.SortOrder
+	var after, before *dto.Cursor
 	var err error
 	for param, value := range params {
+		if param == "after" && len(value) != 0 && value[0] != "" {
+			after, err = dto.DecodeCursor(value[0])
+			if err != nil {
+				return nil, fmt.Errorf("invalid after was provided: %s", err.Error())
+			}
+		}
+
+		if param == "before" && len(value) != 0 && value[0] != "" {
+			before, err = dto.DecodeCursor(value[0])
+			if err != nil {
+				return nil, fmt.Errorf("invalid before was provided: %s", err.Error())
+			}
+		}
+
+		if param == "sort" && len(value) != 0 {
+			sort, err = dto.ParseSortOrder(value[0])
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if param == "thread" && len(value) != 0 {
+			threadKey = value[0]
+		}
+
 		if param == "parent" && len(value) != 0 {
 			id, err = strconv.Atoi(value[0])
 			if err != nil {
@@ -31,18 +61,108 @@ func parserQueryParameters(params url.Values) (*dto.CommentsPagination, error) {
 		if param == "limit" && len(value) != 0 {
 			limit, _ = strconv.Atoi(value[0])
 		}
+
+		if param == "max_depth" && len(value) != 0 {
+			maxDepth, err = strconv.Atoi(value[0])
+			if err != nil || maxDepth < 0 {
+				return nil, fmt.Errorf("invalid max_depth was provided: %q", value[0])
+			}
+		}
+
+		if param == "max_children" && len(value) != 0 {
+			maxChildren, err = strconv.Atoi(value[0])
+			if err != nil || maxChildren < 0 {
+				return nil, fmt.Errorf("invalid max_children was provided: %q", value[0])
+			}
+		}
+	}
+
+	if after != nil && before != nil {
+		return nil, errors.New("only one of after and before can be provided")
+	}
+
+	if maxChildren != 0 && !sort.Chronological() {
+		return nil, fmt.Errorf("%w: %q cannot be combined with max_children, use %s or %s", dto.ErrInvalidSort, sort, dto.SortOldest, dto.SortNewest)
 	}
 
 	var config dto.CommentsPagination
+	config.ThreadKey = threadKeyOrDefault(threadKey)
 	config.ParentID = id
 	config.Page = page
 	config.Limit = limit
+	config.After = after
+	config.Before = before
+	config.MaxDepth = maxDepth
+	config.MaxChildren = maxChildren
+	config.Sort = sort
 
 	return &config, nil
 }
 
-func (h *Handler) getCommentsById(id int, c *ginext.Context) {
-	comments, err := h.service.GetCommentsById(id)
+// parseContextParameters reads the up, down and sort parameters of the
+// context of a comment, falling back to the default number of levels.
+func parseContextParameters(params url.Values) (*dto.CommentContext, error) {
+	config := dto.CommentContext{
+		Up:   dto.DefaultContextUp,
+		Down: dto.DefaultContextDown,
+	}
+
+	var err error
+	if value := params.Get("up"); value != "" {
+		config.Up, err = strconv.Atoi(value)
+		if err != nil || config.Up < 0 {
+			return nil, fmt.Errorf("invalid up was provided: %q", value)
+		}
+	}
+
+	if value := params.Get("down"); value != "" {
+		config.Down, err = strconv.Atoi(value)
+		if err != nil || config.Down < 0 {
+			return nil, fmt.Errorf("invalid down was provided: %q", value)
+		}
+	}
+
+	config.Sort, err = dto.ParseSortOrder(params.Get("sort"))
+	if err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+func parseSuggestParameters(params url.Values) (*dto.Suggest, error) {
+	suggest := dto.Suggest{
+		ThreadKey: threadKeyOrDefault(params.Get("thread")),
+		Query:     params.Get("q"),
+		Limit:     dto.DefaultSuggestLimit,
+		Lang:      params.Get("lang"),
+	}
+
+	if value := params.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > dto.MaxSuggestLimit {
+			return nil, fmt.Errorf("invalid limit was provided: %q", value)
+		}
+		suggest.Limit = limit
+	}
+
+	return &suggest, nil
+}
+
+func commentIDParam(c *ginext.Context) (int, error) {
+	return strconv.Atoi(c.Param("id"))
+}
+
+func threadKeyOrDefault(threadKey string) string {
+	threadKey = strings.TrimSpace(threadKey)
+	if threadKey == "" {
+		return dto.DefaultThreadKey
+	}
+	return threadKey
+}
+
+func (h *Handler) getCommentsById(config dto.CommentsPagination, c *ginext.Context) {
+	comments, err := h.service.GetCommentsById(c.Request.Context(), config)
 	if err != nil {
 		if errors.Is(err, repository.ErrNotSuchComment) {
 			zlog.Logger.Error().Msgf("invalid id: %s", err.Error())
@@ -61,3 +181,126 @@ func (h *Handler) getCommentsById(id int, c *ginext.Context) {
 
 	c.JSON(http.StatusOK, comments)
 }
+
+func (h *Handler) getCommentsTree(config dto.CommentsPagination, c *ginext.Context) {
+	comments, err := h.service.GetCommentsTree(c.Request.Context(), config)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotSuchComment) {
+			zlog.Logger.Error().Msgf("invalid id: %s", err.Error())
+			c.JSON(http.StatusBadRequest, ginext.H{
+				"error": "invalid id: " + err.Error(),
+			})
+			return
+		}
+
+		zlog.Logger.Error().Msgf("could not get comments tree: %s", err.Error())
+		c.JSON(http.StatusInternalServerError, ginext.H{
+			"error": "could not get comments: " + err.Error(),
+		})
+		return
+	}
+
+	setMoreLinks(c.Request.URL, comments)
+
+	c.JSON(http.StatusOK, comments)
+}
+
+// setMoreLinks points every MoreReplies marker in the tree at the request
+// that loads the replies following its cursor, keeping the tree limits.
+func setMoreLinks(u *url.URL, comments []*model.Comment) {
+	for _, comment := range comments {
+		if comment.More != nil {
+			query := u.Query()
+			query.Set("parent", strconv.Itoa(comment.ID))
+			query.Del("after")
+			if comment.More.After != nil {
+				query.Set("after", *comment.More.After)
+			}
+			comment.More.Next = u.Path + "?" + query.Encode()
+		}
+
+		setMoreLinks(u, comment.Children)
+	}
+}
+
+func (h *Handler) getThreadComments(threadKey string, c *ginext.Context) {
+	config, err := parserQueryParameters(c.Request.URL.Query())
+	if err != nil {
+		zlog.Logger.Error().Msg(err.Error())
+		c.JSON(http.StatusBadRequest, ginext.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	config.ThreadKey = threadKey
+	config.ViewerID = viewerID(c)
+
+	page, err := h.service.GetAllComments(c.Request.Context(), *config)
+	if err != nil {
+		zlog.Logger.Error().Msgf("could not get all comments from db: %s", err.Error())
+		c.JSON(http.StatusBadRequest, ginext.H{
+			"error": "could not get comments: " + err.Error(),
+		})
+		return
+	}
+
+	writePage(c, page)
+}
+
+func (h *Handler) getCommentsByCursor(config dto.CommentsPagination, c *ginext.Context) {
+	page, err := h.service.GetCommentsByCursor(c.Request.Context(), config)
+	if err != nil {
+		zlog.Logger.Error().Msgf("could not get comments by cursor: %s", err.Error())
+		c.JSON(http.StatusInternalServerError, ginext.H{
+			"error": "could not get comments: " + err.Error(),
+		})
+		return
+	}
+
+	writePage(c, page)
+}
+
+// writePage fills the navigation links of page from the request URL,
+// repeats them in the Link header and responds with the page.
+func writePage(c *ginext.Context, page *model.CommentsPage) {
+	u := c.Request.URL
+	if page.Page == 0 {
+		if page.NextCursor != nil {
+			page.Next = pageLink(u, "after", *page.NextCursor, "before")
+		}
+		if page.PrevCursor != nil {
+			page.Prev = pageLink(u, "before", *page.PrevCursor, "after")
+		}
+	} else {
+		if page.HasMore {
+			page.Next = pageLink(u, "page", strconv.Itoa(page.Page+1), "")
+		}
+		if page.Page > 1 {
+			page.Prev = pageLink(u, "page", strconv.Itoa(page.Page-1), "")
+		}
+	}
+
+	var links []string
+	if page.Next != nil {
+		links = append(links, fmt.Sprintf(`<%s>; rel="next"`, *page.Next))
+	}
+	if page.Prev != nil {
+		links = append(links, fmt.Sprintf(`<%s>; rel="prev"`, *page.Prev))
+	}
+	if len(links) != 0 {
+		c.Header("Link", strings.Join(links, ", "))
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// pageLink returns the request path with param set to value and drop
+// removed from the query.
+func pageLink(u *url.URL, param, value, drop string) *string {
+	query := u.Query()
+	query.Set(param, value)
+	query.Del(drop)
+
+	link := u.Path + "?" + query.Encode()
+	return &link
+}
@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Komilov31/comment-tree/internal/dto"
+	_ "github.com/Komilov31/comment-tree/internal/model"
+	"github.com/Komilov31/comment-tree/internal/repository"
+	"github.com/wb-go/wbf/ginext"
+	"github.com/wb-go/wbf/zlog"
+)
+
+// @Summary Проголосовать за комментарий
+// @Description Ставит голос "за" (up) или "против" (down) либо отменяет голос (clear). У пользователя может быть только один голос за комментарий
+// @Tags votes
+// @Accept json
+// @Produce json
+// @Param id path int true "ID комментария"
+// @Param X-User-Id header string true "ID пользователя"
+// @Param vote body dto.Vote true "Голос: up, down или clear"
+// @Success 200 {object} model.Votes "Итог голосования по комментарию"
+// @Failure 400 {object} map[string]string "error":"invalid payload" or "invalid vote"
+// @Failure 401 {object} map[string]string "error":"authentication required"
+// @Failure 404 {object} map[string]string "error":"there is not comment with such id"
+// @Failure 500 {object} map[string]string "error":"could not vote"
+// @Router /comments/{id}/vote [post]
+func (h *Handler) VoteComment(c *ginext.Context) {
+	commentId, err := commentIDParam(c)
+	if err != nil {
+		zlog.Logger.Error().Msgf("invalid id was provided: %s", err.Error())
+		c.JSON(http.StatusBadRequest, ginext.H{
+			"error": "invalid id was provided",
+		})
+		return
+	}
+
+	user, err := currentUser(c)
+	if err != nil {
+		zlog.Logger.Error().Msgf("could not identify voter: %s", err.Error())
+		c.JSON(http.StatusUnauthorized, ginext.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	var vote dto.Vote
+	if err := c.BindJSON(&vote); err != nil {
+		zlog.Logger.Error().Msgf("could not unmarshal request body to model: %s", err.Error())
+		c.JSON(http.StatusBadRequest, ginext.H{
+			"error": "invalid payload: " + err.Error(),
+		})
+		return
+	}
+
+	votes, err := h.service.Vote(c.Request.Context(), commentId, user.ID, vote)
+	if err != nil {
+		zlog.Logger.Error().Msgf("could not vote for comment: %s", err.Error())
+		switch {
+		case errors.Is(err, dto.ErrInvalidVote):
+			c.JSON(http.StatusBadRequest, ginext.H{
+				"error": err.Error(),
+			})
+		case errors.Is(err, repository.ErrNotSuchComment):
+			c.JSON(http.StatusNotFound, ginext.H{
+				"error": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, ginext.H{
+				"error": "could not vote: " + err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, votes)
+}
@@ -2,10 +2,137 @@ package model
 
 import "time"
 
+type Author struct {
+	ID          string  `json:"id"`
+	DisplayName string  `json:"display_name"`
+	AvatarURL   *string `json:"avatar_url"`
+}
+
 type Comment struct {
 	ID        int        `json:"id"`
 	ParentID  *int       `json:"parent_id"`
+	ThreadKey string     `json:"thread_key"`
+	Depth     int        `json:"depth"`
+	Language  string     `json:"language"`
+	Author    *Author    `json:"author"`
 	Text      string     `json:"text"`
 	CreatedAt time.Time  `json:"created_at"`
+	EditedAt  *time.Time `json:"edited_at"`
+	Deleted   bool       `json:"deleted"`
 	Children  []*Comment `json:"children"`
+
+	// ReplyCount counts direct replies, DescendantCount the whole subtree
+	// and LastReplyAt is the time of its newest comment. They are kept by
+	// the database and do not depend on which children were loaded.
+	ReplyCount      int        `json:"reply_count"`
+	DescendantCount int        `json:"descendant_count"`
+	LastReplyAt     *time.Time `json:"last_reply_at"`
+
+	// ChildrenCount is the former name of ReplyCount, still returned so
+	// that clients of depth-limited trees keep working.
+	//
+	// Deprecated: use ReplyCount.
+	ChildrenCount int `json:"children_count,omitempty"`
+
+	Votes
+	Reactions []Reaction `json:"reactions"`
+
+	// More is only filled for depth-limited trees.
+	More *MoreReplies `json:"more,omitempty"`
+
+	// Rank, Snippet, RootID and Ancestors are only filled for search hits,
+	// Snippet when highlighting was asked for. Ancestors run from the root
+	// of the thread down to the parent of the hit and are empty for roots.
+	Rank      *float64   `json:"rank,omitempty"`
+	Snippet   *string    `json:"snippet,omitempty"`
+	RootID    *int       `json:"root_id,omitempty"`
+	Ancestors []*Comment `json:"ancestors,omitempty"`
+}
+
+// BuildInfo describes the running binary. Commit and CommitTime are taken
+// from version control and are empty when the binary was built without it.
+type BuildInfo struct {
+	Version    string `json:"version"`
+	Commit     string `json:"commit"`
+	CommitTime string `json:"commit_time"`
+	GoVersion  string `json:"go_version"`
+}
+
+// Readiness is the state of the dependencies of the service: the migration
+// version of the database against the newest migration shipped with it.
+type Readiness struct {
+	Status            string `json:"status"`
+	Migration         int64  `json:"migration"`
+	ExpectedMigration int64  `json:"expected_migration"`
+	Error             string `json:"error,omitempty"`
+}
+
+// Suggestions are the autocomplete results for a partly typed search query:
+// excerpts of the best matching comments and the most frequent terms
+// starting with the last word of the query.
+type Suggestions struct {
+	Comments []Suggestion `json:"comments"`
+	Terms    []string     `json:"terms"`
+}
+
+// Suggestion is a comment matching a partly typed query, with an Excerpt of
+// its text around the match.
+type Suggestion struct {
+	ID      int    `json:"id"`
+	Excerpt string `json:"excerpt"`
+}
+
+// Votes is the vote tally of a comment. MyVote is the vote of the caller:
+// 1, -1 or 0 when they have not voted or are anonymous.
+type Votes struct {
+	Score     int `json:"score"`
+	Upvotes   int `json:"upvotes"`
+	Downvotes int `json:"downvotes"`
+	MyVote    int `json:"my_vote"`
+}
+
+// Reaction is the number of users who reacted to a comment with Emoji.
+// Reacted tells whether the caller is one of them.
+type Reaction struct {
+	Emoji   string `json:"emoji"`
+	Count   int    `json:"count"`
+	Reacted bool   `json:"reacted"`
+}
+
+// MoreReplies marks a comment whose replies were cut off by max_depth or
+// max_children. After is the cursor of the last loaded reply and Next is
+// the request that loads the replies following it.
+type MoreReplies struct {
+	Remaining int     `json:"remaining"`
+	After     *string `json:"after"`
+	Next      string  `json:"next"`
+}
+
+// CommentsPage is one page of comments. Offset pages carry Page and
+// TotalCount, keyset pages carry the cursors. Next and Prev are links to
+// the neighbouring pages and are nil when there is nothing in that direction.
+type CommentsPage struct {
+	Items      []*Comment `json:"items"`
+	Page       int        `json:"page,omitempty"`
+	Limit      int        `json:"limit,omitempty"`
+	TotalCount *int       `json:"total_count,omitempty"`
+	HasMore    bool       `json:"has_more"`
+	Next       *string    `json:"next"`
+	Prev       *string    `json:"prev"`
+	NextCursor *string    `json:"next_cursor,omitempty"`
+	PrevCursor *string    `json:"prev_cursor,omitempty"`
+}
+
+// Revision is one version of a comment text. Diff describes how the
+// version differs from the one before it.
+type Revision struct {
+	Version   int       `json:"version"`
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at"`
+	Diff      []DiffOp  `json:"diff"`
+}
+
+type DiffOp struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
 }
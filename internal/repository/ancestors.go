@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Komilov31/comment-tree/internal/dto"
+	"github.com/Komilov31/comment-tree/internal/model"
+	"github.com/lib/pq"
+)
+
+// GetCommentAncestors returns the comments above comment id, from the root
+// of its thread down to its parent. A root comment has no ancestors.
+func (r *Repository) GetCommentAncestors(ctx context.Context, id int, viewerID string) ([]model.Comment, error) {
+	query := `SELECT ` + commentColumns + ` FROM comments c
+	LEFT JOIN authors a ON a.id = c.author_id
+	WHERE c.path @> (SELECT path FROM comments WHERE id = $1)
+	ORDER BY c.depth ASC;`
+
+	rows, err := r.db.Master.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("could not get comment ancestors from db: %w", err)
+	}
+	defer rows.Close()
+
+	comments, err := scanComments(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	// the last row is the comment itself
+	if len(comments) == 0 {
+		return nil, ErrNotSuchComment
+	}
+	ancestors := comments[:len(comments)-1]
+
+	if err := r.loadCommentDetails(ctx, ancestors, viewerID); err != nil {
+		return nil, err
+	}
+
+	return ancestors, nil
+}
+
+// loadHitAncestors fills in the root id and the ancestors of every search
+// hit, so that a reply is shown in its context even when its parent did not
+// match. The ancestors are loaded without reactions and votes of the viewer.
+func (r *Repository) loadHitAncestors(ctx context.Context, hits []model.Comment) error {
+	if len(hits) == 0 {
+		return nil
+	}
+
+	index := make(map[int]*model.Comment, len(hits))
+	ids := make([]int64, len(hits))
+	for i := range hits {
+		hits[i].RootID = &hits[i].ID
+		index[hits[i].ID] = &hits[i]
+		ids[i] = int64(hits[i].ID)
+	}
+
+	query := `SELECT ` + commentColumns + `, h.id FROM comments h
+	INNER JOIN comments c ON c.path @> h.path AND c.id <> h.id
+	LEFT JOIN authors a ON a.id = c.author_id
+	WHERE h.id = ANY($1)
+	ORDER BY h.id, c.depth ASC`
+
+	rows, err := r.db.Master.QueryContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		return fmt.Errorf("could not get search hit ancestors from db: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var ancestor model.Comment
+		var hitID int
+		if err := scanComment(rows, &ancestor, &hitID); err != nil {
+			return err
+		}
+
+		hit := index[hitID]
+		if len(hit.Ancestors) == 0 {
+			hit.RootID = &ancestor.ID
+		}
+		hit.Ancestors = append(hit.Ancestors, &ancestor)
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("could not read rows from db: %w", err)
+	}
+
+	return nil
+}
+
+// GetCommentContext returns the thread of comment config.ID pruned to the
+// chain from its root down to the comment, the siblings on the config.Up
+// levels nearest to it and config.Down levels of its replies.
+func (r *Repository) GetCommentContext(ctx context.Context, config dto.CommentContext) ([]*model.Comment, error) {
+	query := `WITH focus AS (
+		SELECT path, depth FROM comments WHERE id = $1
+	),
+	context_ids AS (
+		SELECT c.id FROM comments c, focus f
+		WHERE c.path @> f.path
+
+		UNION
+
+		SELECT c.id FROM comments c
+		INNER JOIN comments p ON p.id = c.parent_id, focus f
+		WHERE p.path @> f.path AND p.depth BETWEEN f.depth - $2 AND f.depth - 1
+
+		UNION
+
+		SELECT c.id FROM comments c, focus f
+		WHERE c.path <@ f.path AND c.depth <= f.depth + $3
+	)
+	SELECT ` + commentColumns + ` FROM comments c
+	LEFT JOIN authors a ON a.id = c.author_id
+	WHERE c.id IN (SELECT id FROM context_ids)
+	ORDER BY ` + orderBy(dto.SortOldest) + `;`
+
+	rows, err := r.db.Master.QueryContext(ctx, query, config.ID, config.Up, config.Down)
+	if err != nil {
+		return nil, fmt.Errorf("could not get comment context from db: %w", err)
+	}
+	defer rows.Close()
+
+	comments, err := scanComments(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(comments) == 0 {
+		return nil, ErrNotSuchComment
+	}
+
+	if err := r.loadCommentDetails(ctx, comments, config.ViewerID); err != nil {
+		return nil, err
+	}
+
+	commentTree := buildTree(comments)
+	sortTree(commentTree, config.Sort)
+
+	return commentTree, nil
+}
@@ -1,18 +1,43 @@
 package repository
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/Komilov31/comment-tree/internal/dto"
 )
 
-func (r *Repository) CreateComment(comment dto.CreateComment) (*dto.CreateComment, error) {
-	query := `INSERT INTO comments(parent_id, text) 
-	VALUES ($1, $2) 
-	RETURNING id, created_at`
+func (r *Repository) CreateComment(ctx context.Context, comment dto.CreateComment) (*dto.CreateComment, error) {
+	tx, err := r.db.Master.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var authorID *string
+	if comment.Author != nil {
+		query := `INSERT INTO authors(id, display_name, avatar_url)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (id) DO UPDATE
+		SET display_name = EXCLUDED.display_name,
+			avatar_url = EXCLUDED.avatar_url,
+			updated_at = CURRENT_TIMESTAMP`
 
-	err := r.db.Master.QueryRow(query, comment.ParentID, comment.Text).Scan(
+		_, err := tx.ExecContext(ctx, query, comment.Author.ID, comment.Author.DisplayName, comment.Author.AvatarURL)
+		if err != nil {
+			return nil, fmt.Errorf("could not save author in db: %w", err)
+		}
+		authorID = &comment.Author.ID
+	}
+
+	// replies always live in the thread of their parent
+	query := `INSERT INTO comments(parent_id, thread_key, author_id, text, language, search_config) 
+	VALUES ($1, COALESCE((SELECT thread_key FROM comments WHERE id = $1), $2), $3, $4, $5, $6::regconfig) 
+	RETURNING id, thread_key, created_at`
+
+	err = tx.QueryRowContext(ctx, query, comment.ParentID, comment.ThreadKey, authorID, comment.Text, comment.Language, comment.SearchConfig).Scan(
 		&comment.ID,
+		&comment.ThreadKey,
 		&comment.CreatedAt,
 	)
 	if err != nil {
@@ -22,5 +47,9 @@ func (r *Repository) CreateComment(comment dto.CreateComment) (*dto.CreateCommen
 		return nil, fmt.Errorf("could not create comment in db: %w", err)
 	}
 
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("could not commit transaction: %w", err)
+	}
+
 	return &comment, nil
 }
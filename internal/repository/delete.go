@@ -1,14 +1,98 @@
 package repository
 
-import "fmt"
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/Komilov31/comment-tree/internal/dto"
+)
+
+// DeleteCommentById removes the comment and, through the foreign key
+// cascade, every reply beneath it. The removed subtree is kept in the trash
+// until it is restored or purged.
+func (r *Repository) DeleteCommentById(ctx context.Context, id int, requester dto.Requester) error {
+	tx, err := r.db.Master.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("could not begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := lockCommentForRequester(ctx, tx, id, requester, true); err != nil {
+		return err
+	}
+
+	if err := moveSubtreeToTrash(ctx, tx, id); err != nil {
+		return err
+	}
 
-func (r *Repository) DeleteCommentById(id int) error {
 	query := "DELETE FROM comments WHERE id = $1"
 
-	_, err := r.db.Master.Exec(query, id)
+	_, err = tx.ExecContext(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("could not delete notification from db: %w", err)
 	}
 
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("could not commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// SoftDeleteCommentById turns the comment into a tombstone and leaves its
+// replies in place.
+func (r *Repository) SoftDeleteCommentById(ctx context.Context, id int, requester dto.Requester) error {
+	tx, err := r.db.Master.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("could not begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := lockCommentForRequester(ctx, tx, id, requester, false); err != nil {
+		return err
+	}
+
+	query := "UPDATE comments SET deleted_at = CURRENT_TIMESTAMP WHERE id = $1"
+
+	_, err = tx.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("could not soft delete comment in db: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("could not commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// lockCommentForRequester locks the comment row and checks that the
+// requester is allowed to change it. Admins may change any comment.
+func lockCommentForRequester(ctx context.Context, tx *sql.Tx, id int, requester dto.Requester, allowDeleted bool) error {
+	var owner sql.NullString
+	var deleted bool
+	query := `SELECT author_id, deleted_at IS NOT NULL
+	FROM comments
+	WHERE id = $1
+	FOR UPDATE`
+
+	err := tx.QueryRowContext(ctx, query, id).Scan(&owner, &deleted)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotSuchComment
+		}
+		return fmt.Errorf("could not get comment from db: %w", err)
+	}
+
+	if deleted && !allowDeleted {
+		return ErrNotSuchComment
+	}
+
+	if !requester.Admin && (!owner.Valid || owner.String != requester.ID) {
+		return ErrNotAuthor
+	}
+
 	return nil
 }
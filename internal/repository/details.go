@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/Komilov31/comment-tree/internal/model"
+	"github.com/lib/pq"
+)
+
+// querier is satisfied by both *sql.DB and *sql.Tx.
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// loadCommentDetails fills in the parts of comments stored outside the
+// comments table: reactions and, for a known viewer, their own votes.
+// Tombstones of soft deleted comments are left without them.
+func (r *Repository) loadCommentDetails(ctx context.Context, comments []model.Comment, viewerID string) error {
+	index := make(map[int]*model.Comment, len(comments))
+	ids := make([]int64, 0, len(comments))
+	for i := range comments {
+		if comments[i].Deleted {
+			continue
+		}
+		comments[i].Reactions = []model.Reaction{}
+		index[comments[i].ID] = &comments[i]
+		ids = append(ids, int64(comments[i].ID))
+	}
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	reactions, err := queryReactions(ctx, r.db.Master, ids, viewerID)
+	if err != nil {
+		return err
+	}
+	for id, commentReactions := range reactions {
+		index[id].Reactions = commentReactions
+	}
+
+	if viewerID == "" {
+		return nil
+	}
+
+	query := "SELECT comment_id, value FROM comment_votes WHERE user_id = $1 AND comment_id = ANY($2)"
+	rows, err := r.db.Master.QueryContext(ctx, query, viewerID, pq.Array(ids))
+	if err != nil {
+		return fmt.Errorf("could not get votes from db: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var commentID, value int
+		if err := rows.Scan(&commentID, &value); err != nil {
+			return fmt.Errorf("could not scan row to model: %w", err)
+		}
+		index[commentID].MyVote = value
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("could not read rows from db: %w", err)
+	}
+
+	return nil
+}
+
+// queryReactions returns the reactions of the given comments grouped by
+// emoji, in the order the emoji were first used on each comment.
+func queryReactions(ctx context.Context, q querier, ids []int64, viewerID string) (map[int][]model.Reaction, error) {
+	query := `SELECT comment_id, emoji, COUNT(*), BOOL_OR(user_id = $2)
+	FROM comment_reactions
+	WHERE comment_id = ANY($1)
+	GROUP BY comment_id, emoji
+	ORDER BY comment_id, MIN(created_at), emoji`
+
+	rows, err := q.QueryContext(ctx, query, pq.Array(ids), viewerID)
+	if err != nil {
+		return nil, fmt.Errorf("could not get reactions from db: %w", err)
+	}
+	defer rows.Close()
+
+	reactions := make(map[int][]model.Reaction)
+	for rows.Next() {
+		var commentID int
+		var reaction model.Reaction
+		if err := rows.Scan(&commentID, &reaction.Emoji, &reaction.Count, &reaction.Reacted); err != nil {
+			return nil, fmt.Errorf("could not scan row to model: %w", err)
+		}
+		reactions[commentID] = append(reactions[commentID], reaction)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not read rows from db: %w", err)
+	}
+
+	return reactions, nil
+}
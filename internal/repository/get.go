@@ -1,7 +1,11 @@
 package repository
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
+	"slices"
+	"strconv"
 
 	"github.com/Komilov31/comment-tree/internal/dto"
 	"github.com/Komilov31/comment-tree/internal/model"
@@ -11,158 +15,319 @@ var (
 	defaultLimit = 10
 )
 
-func (r *Repository) GetCommentsById(id int) ([]*model.Comment, error) {
-	query := `WITH RECURSIVE comment_tree AS (
-  	SELECT id, parent_id, text, created_at
- 	FROM comments
-  	WHERE id = $1 
-  
- 	UNION
-
-  	SELECT c.id, c.parent_id, c.text, c.created_at
-  	FROM comments c
-  	INNER JOIN comment_tree ct ON c.parent_id = ct.id
-	)
-	SELECT * FROM comment_tree;`
-
-	rows, err := r.db.Master.Query(query, id)
+// subtreeFilter keeps comment $1 of thread $2 together with all of its
+// replies: the comments whose materialized path starts with its path.
+const subtreeFilter = `c.thread_key = $2
+	AND c.path <@ (SELECT path FROM comments WHERE id = $1 AND thread_key = $2)`
+
+// GetCommentsById returns comment config.ParentID with all of its replies.
+func (r *Repository) GetCommentsById(ctx context.Context, config dto.CommentsPagination) ([]*model.Comment, error) {
+	query := `SELECT ` + commentColumns + ` FROM comments c
+	LEFT JOIN authors a ON a.id = c.author_id
+	WHERE ` + subtreeFilter + `;`
+
+	rows, err := r.db.Master.QueryContext(ctx, query, config.ParentID, config.ThreadKey)
 	if err != nil {
 		return nil, fmt.Errorf("could not get comments from db: %w", err)
 	}
 	defer rows.Close()
 
-	var comments []model.Comment
-	for rows.Next() {
-		var comment model.Comment
-		err := rows.Scan(
-			&comment.ID,
-			&comment.ParentID,
-			&comment.Text,
-			&comment.CreatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("could not scan row to model: %w", err)
-		}
-
-		comments = append(comments, comment)
+	comments, err := scanComments(rows)
+	if err != nil {
+		return nil, err
 	}
 
 	if len(comments) == 0 {
 		return nil, ErrNotSuchComment
 	}
 
+	if err := r.loadCommentDetails(ctx, comments, config.ViewerID); err != nil {
+		return nil, err
+	}
+
 	commentTree := buildTree(comments)
+	sortTree(commentTree, config.Sort)
 
 	return commentTree, nil
 }
 
-func (r *Repository) GetCommentsPaginated(config dto.CommentsPagination) ([]*model.Comment, error) {
-	if config.Limit != 0 {
-		defaultLimit = config.Limit
+// GetCommentsPaginated returns one page of the subtree of config.ParentID
+// (or of the whole thread when it is zero) in creation order, together with
+// the total number of comments in it.
+func (r *Repository) GetCommentsPaginated(ctx context.Context, config dto.CommentsPagination) (*model.CommentsPage, error) {
+	page, limit := config.Page, config.Limit
+	if limit == 0 && page > 0 {
+		limit = defaultLimit
+	}
+	if page < 1 {
+		page = 1
+	}
+
+	query, args := commentsQuery(config, commentColumns+", COUNT(*) OVER()")
+	query += " ORDER BY " + orderBy(config.Sort)
+	if limit > 0 {
+		args = append(args, limit, (page-1)*limit)
+		query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+	}
+
+	rows, err := r.db.Master.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("could not get comments from db: %w", err)
+	}
+	defer rows.Close()
+
+	var total int
+	comments, err := scanComments(rows, &total)
+	if err != nil {
+		return nil, err
+	}
+
+	// a page past the end has no rows to carry the window count
+	if len(comments) == 0 && page > 1 {
+		countQuery, countArgs := commentsQuery(config, "COUNT(*)")
+		if err := r.db.Master.QueryRowContext(ctx, countQuery, countArgs...).Scan(&total); err != nil {
+			return nil, fmt.Errorf("could not count comments in db: %w", err)
+		}
+	}
+
+	if err := r.loadCommentDetails(ctx, comments, config.ViewerID); err != nil {
+		return nil, err
+	}
+
+	result := newOffsetPage(comments, buildTree(comments), page, limit, total)
+	sortTree(result.Items, config.Sort)
+
+	return result, nil
+}
+
+// GetCommentsByCursor returns one page of the subtree of config.ParentID
+// (or of the whole thread when it is zero) in (created_at, id) order,
+// starting right after config.After or ending right before config.Before.
+func (r *Repository) GetCommentsByCursor(ctx context.Context, config dto.CommentsPagination) (*model.CommentsPage, error) {
+	limit := defaultLimit
+	if config.Limit > 0 {
+		limit = config.Limit
 	}
 
-	offset := (config.Page - 1) * defaultLimit
+	cursor, compare, order := config.After, ">", "ASC"
+	if config.Before != nil {
+		cursor, compare, order = config.Before, "<", "DESC"
+	}
 
-	query := `WITH RECURSIVE comment_tree AS (
-  	SELECT id, parent_id, text, created_at
- 	FROM comments
-	WHERE id = $1
+	query, args := commentsQuery(config, commentColumns)
 
- 	UNION
+	if cursor != nil {
+		args = append(args, cursor.CreatedAt, cursor.ID)
+		query += fmt.Sprintf(" AND (c.created_at, c.id) %s ($%d, $%d)", compare, len(args)-1, len(args))
+	}
 
-  	SELECT c.id, c.parent_id, c.text, c.created_at
-  	FROM comments c
-  	INNER JOIN comment_tree ct ON c.parent_id = ct.id
-	)
-	SELECT * FROM comment_tree 
-	ORDER BY created_at ASC
-	LIMIT $2 OFFSET $3;`
+	// one extra row tells whether there is anything past this page
+	args = append(args, limit+1)
+	query += fmt.Sprintf(" ORDER BY c.created_at %s, c.id %s LIMIT $%d", order, order, len(args))
 
-	rows, err := r.db.Master.Query(query, config.ParentID, defaultLimit, offset)
+	rows, err := r.db.Master.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("could not get comments from db: %w", err)
 	}
 	defer rows.Close()
 
-	var comments []model.Comment
-	for rows.Next() {
-		var comment model.Comment
-		err := rows.Scan(
-			&comment.ID,
-			&comment.ParentID,
-			&comment.Text,
-			&comment.CreatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("could not scan row to model: %w", err)
-		}
+	comments, err := scanComments(rows)
+	if err != nil {
+		return nil, err
+	}
 
-		comments = append(comments, comment)
+	hasMore := len(comments) > limit
+	if hasMore {
+		comments = comments[:limit]
+	}
+	if config.Before != nil {
+		slices.Reverse(comments)
 	}
 
-	commentTree := buildTree(comments)
+	if err := r.loadCommentDetails(ctx, comments, config.ViewerID); err != nil {
+		return nil, err
+	}
 
-	return commentTree, nil
+	page := &model.CommentsPage{
+		Items:   buildTree(comments),
+		Limit:   limit,
+		HasMore: hasMore,
+	}
+	sortTree(page.Items, config.Sort)
+	if len(comments) == 0 {
+		return page, nil
+	}
+
+	first := dto.Cursor{CreatedAt: comments[0].CreatedAt, ID: comments[0].ID}.Encode()
+	last := dto.Cursor{CreatedAt: comments[len(comments)-1].CreatedAt, ID: comments[len(comments)-1].ID}.Encode()
+	if config.Before != nil {
+		page.NextCursor = &last
+		if hasMore {
+			page.PrevCursor = &first
+		}
+	} else {
+		if hasMore {
+			page.NextCursor = &last
+		}
+		if config.After != nil {
+			page.PrevCursor = &first
+		}
+	}
+
+	return page, nil
+}
+
+// GetAllComments returns the comments of a whole thread, paginated when
+// config has a page or a limit.
+func (r *Repository) GetAllComments(ctx context.Context, config dto.CommentsPagination) (*model.CommentsPage, error) {
+	config.ParentID = 0
+	return r.GetCommentsPaginated(ctx, config)
 }
 
-func (r *Repository) GetAllComments() ([]*model.Comment, error) {
-	query := "SELECT id, parent_id, text, created_at FROM comments"
+// GetCommentsByTextSearch returns one page of the comments of a thread
+// matching search.Text together with their rank and, when
+// search.HighlightOptions are set, a ts_headline snippet. Every comment is
+// matched with the text search configuration of its language, out of
+// search.SearchConfigs, or by trigrams in the fuzzy modes.
+func (r *Repository) GetCommentsByTextSearch(ctx context.Context, search dto.SearchText) (*model.CommentsPage, error) {
+	page, limit := search.Page, search.Limit
+	if limit == 0 && page > 0 {
+		limit = defaultLimit
+	}
+	if page < 1 {
+		page = 1
+	}
+
+	filter, args := searchFilter(search)
+	countArgs := args
 
-	rows, err := r.db.Master.Query(query)
+	rank := searchRank(search.Mode)
+	snippet := "NULL"
+	if search.HighlightOptions != "" {
+		args = append(args, search.HighlightOptions)
+		snippet = fmt.Sprintf("ts_headline(c.search_config, COALESCE(c.text, ''), websearch_to_tsquery(c.search_config, $1), $%d)", len(args))
+	}
+
+	query := `SELECT ` + commentColumns + `, ` + rank + `, ` + snippet + `, COUNT(*) OVER()
+	FROM comments c
+	LEFT JOIN authors a ON a.id = c.author_id
+	WHERE ` + filter
+	// without an explicit sort the most relevant comments come first
+	if search.Sort != "" {
+		query += " ORDER BY " + orderBy(search.Sort)
+	} else {
+		query += " ORDER BY " + rank + " DESC, c.id ASC"
+	}
+	if limit > 0 {
+		args = append(args, limit, (page-1)*limit)
+		query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+	}
+
+	tx, err := r.db.Master.BeginTx(ctx, nil)
 	if err != nil {
-		return nil, fmt.Errorf("could not get comments from db: %w", err)
+		return nil, fmt.Errorf("could not begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if isFuzzySearch(search.Mode) {
+		threshold := strconv.FormatFloat(search.Threshold(), 'f', -1, 64)
+		setThreshold := "SELECT set_config('pg_trgm.word_similarity_threshold', $1, true)"
+		if _, err := tx.ExecContext(ctx, setThreshold, threshold); err != nil {
+			return nil, fmt.Errorf("could not set similarity threshold: %w", err)
+		}
+	}
+
+	comments, total, err := querySearchHits(ctx, tx, query, args)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(comments) == 0 && page > 1 {
+		countQuery := "SELECT COUNT(*) FROM comments c WHERE " + filter
+		if err := tx.QueryRowContext(ctx, countQuery, countArgs...).Scan(&total); err != nil {
+			return nil, fmt.Errorf("could not count comments in db: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("could not commit transaction: %w", err)
+	}
+
+	if err := r.loadCommentDetails(ctx, comments, search.ViewerID); err != nil {
+		return nil, err
+	}
+
+	if err := r.loadHitAncestors(ctx, comments); err != nil {
+		return nil, err
+	}
+
+	// hits are not nested under each other but listed in the order of the
+	// query, each one with its own ancestors
+	hits := make([]*model.Comment, len(comments))
+	for i := range comments {
+		hits[i] = &comments[i]
+	}
+
+	return newOffsetPage(comments, hits, page, limit, total), nil
+}
+
+// querySearchHits reads the hits of a search query selecting commentColumns
+// followed by the rank, the snippet and the total count of every row.
+func querySearchHits(ctx context.Context, tx *sql.Tx, query string, args []any) ([]model.Comment, int, error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("could not get comments from db: %w", err)
 	}
 	defer rows.Close()
 
 	var comments []model.Comment
+	var total int
 	for rows.Next() {
 		var comment model.Comment
-		err := rows.Scan(
-			&comment.ID,
-			&comment.ParentID,
-			&comment.Text,
-			&comment.CreatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("could not scan row to model: %w", err)
+		var rank float64
+		var snippet sql.NullString
+		if err := scanComment(rows, &comment, &rank, &snippet, &total); err != nil {
+			return nil, 0, err
 		}
 
+		comment.Rank = &rank
+		if snippet.Valid {
+			comment.Snippet = &snippet.String
+		}
 		comments = append(comments, comment)
 	}
 
-	commentTree := buildTree(comments)
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("could not read rows from db: %w", err)
+	}
 
-	return commentTree, nil
+	return comments, total, nil
 }
 
-func (r *Repository) GetCommentsByTextSearch(text string) ([]*model.Comment, error) {
-	query := `SELECT id, parent_id, text, created_at FROM comments
-	WHERE search_vector @@ plainto_tsquery('russian', $1)
-	ORDER BY ts_rank(search_vector, plainto_tsquery('russian', $1));`
+// GetCommentById returns comment id alone, without its replies.
+func (r *Repository) GetCommentById(ctx context.Context, id int, viewerID string) (*model.Comment, error) {
+	query := `SELECT ` + commentColumns + ` FROM comments c
+	LEFT JOIN authors a ON a.id = c.author_id
+	WHERE c.id = $1;`
 
-	rows, err := r.db.Master.Query(query, text)
+	rows, err := r.db.Master.QueryContext(ctx, query, id)
 	if err != nil {
-		return nil, fmt.Errorf("could not get comments from db: %w", err)
+		return nil, fmt.Errorf("could not get comment from db: %w", err)
 	}
 	defer rows.Close()
 
-	var comments []model.Comment
-	for rows.Next() {
-		var comment model.Comment
-		err := rows.Scan(
-			&comment.ID,
-			&comment.ParentID,
-			&comment.Text,
-			&comment.CreatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("could not scan row to model: %w", err)
-		}
+	comments, err := scanComments(rows)
+	if err != nil {
+		return nil, err
+	}
 
-		comments = append(comments, comment)
+	if len(comments) == 0 {
+		return nil, ErrNotSuchComment
 	}
 
-	commentTree := buildTree(comments)
+	if err := r.loadCommentDetails(ctx, comments, viewerID); err != nil {
+		return nil, err
+	}
 
-	return commentTree, nil
+	return &comments[0], nil
 }
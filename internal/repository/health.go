@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+)
+
+// Ping checks that the database is reachable.
+func (r *Repository) Ping(ctx context.Context) error {
+	if err := r.db.Master.PingContext(ctx); err != nil {
+		return fmt.Errorf("could not reach db: %w", err)
+	}
+	return nil
+}
+
+// MigrationVersion returns the newest goose migration applied to the
+// database and not rolled back since, or 0 when none was.
+func (r *Repository) MigrationVersion(ctx context.Context) (int64, error) {
+	query := `SELECT COALESCE(MAX(v.version_id), 0) FROM goose_db_version v
+	WHERE v.is_applied AND NOT EXISTS (
+		SELECT 1 FROM goose_db_version d
+		WHERE d.version_id = v.version_id AND d.id > v.id AND NOT d.is_applied
+	)`
+
+	var version int64
+	if err := r.db.Master.QueryRowContext(ctx, query).Scan(&version); err != nil {
+		return 0, fmt.Errorf("could not get migration version from db: %w", err)
+	}
+	return version, nil
+}
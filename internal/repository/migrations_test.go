@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// migratedFunction returns the up migration holding the newest definition
+// of the SQL function name, the one a migrated database runs.
+func migratedFunction(t *testing.T, name string) string {
+	t.Helper()
+
+	files, err := filepath.Glob("../../migrations/*.sql")
+	assert.NoError(t, err)
+
+	var definition string
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		assert.NoError(t, err)
+
+		up, _, _ := strings.Cut(string(content), "-- +goose Down")
+		if strings.Contains(up, "FUNCTION "+name+"(") {
+			definition = up
+		}
+	}
+
+	assert.NotEmpty(t, definition, name)
+	return definition
+}
+
+func TestCommentsSetPath_WaitsForMoves(t *testing.T) {
+	lock := fmt.Sprintf("pg_advisory_xact_lock_shared(%d)", moveLockKey)
+	assert.Contains(t, migratedFunction(t, "comments_set_path"), lock)
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/Komilov31/comment-tree/internal/dto"
+	"github.com/Komilov31/comment-tree/internal/model"
+)
+
+// moveLockKey is the advisory lock taken by every move, so that two
+// concurrent moves cannot put two comments under each other. New replies
+// take it in shared mode in the comments_set_path trigger, so that they
+// wait for a move instead of copying the path it is about to change; the
+// trigger repeats the value.
+const moveLockKey = 7305511
+
+// MoveComment puts comment id with all of its replies under comment
+// parentID, or makes it a root when parentID is nil, and returns the moved
+// subtree. A reply always lives in the thread of its parent, so the subtree
+// follows the new parent to its thread.
+func (r *Repository) MoveComment(ctx context.Context, id int, parentID *int, viewerID string) ([]*model.Comment, error) {
+	tx, err := r.db.Master.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1)", moveLockKey); err != nil {
+		return nil, fmt.Errorf("could not lock comments for move: %w", err)
+	}
+
+	var threadKey string
+	query := "SELECT thread_key FROM comments WHERE id = $1 FOR UPDATE"
+	if err := tx.QueryRowContext(ctx, query, id).Scan(&threadKey); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotSuchComment
+		}
+		return nil, fmt.Errorf("could not get comment from db: %w", err)
+	}
+
+	if parentID != nil {
+		// the new parent must not be the moved comment or one of its replies
+		var isReply bool
+		query = `SELECT p.thread_key, p.path <@ c.path
+		FROM comments p, comments c
+		WHERE p.id = $1 AND c.id = $2`
+
+		err := tx.QueryRowContext(ctx, query, *parentID, id).Scan(&threadKey, &isReply)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, ErrInvalidParenID
+			}
+			return nil, fmt.Errorf("could not get new parent from db: %w", err)
+		}
+
+		if isReply {
+			return nil, ErrMoveIntoSubtree
+		}
+	}
+
+	query = "UPDATE comments SET parent_id = $2 WHERE id = $1"
+	if _, err := tx.ExecContext(ctx, query, id, parentID); err != nil {
+		if isForeignKeyViolation(err) {
+			return nil, ErrInvalidParenID
+		}
+		return nil, fmt.Errorf("could not move comment in db: %w", err)
+	}
+
+	// the path of the subtree has been updated by the move
+	query = `UPDATE comments SET thread_key = $2
+	WHERE path <@ (SELECT path FROM comments WHERE id = $1) AND thread_key <> $2`
+
+	if _, err := tx.ExecContext(ctx, query, id, threadKey); err != nil {
+		return nil, fmt.Errorf("could not move replies to thread in db: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("could not commit transaction: %w", err)
+	}
+
+	return r.GetCommentsById(ctx, dto.CommentsPagination{
+		ThreadKey: threadKey,
+		ParentID:  id,
+		ViewerID:  viewerID,
+	})
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Komilov31/comment-tree/internal/model"
+)
+
+// AddReaction records that userID reacted to a comment with emoji and
+// returns the reactions of the comment. Reacting twice is a no-op.
+func (r *Repository) AddReaction(ctx context.Context, id int, userID, emoji string) ([]model.Reaction, error) {
+	query := `INSERT INTO comment_reactions(comment_id, user_id, emoji)
+	VALUES ($1, $2, $3)
+	ON CONFLICT DO NOTHING`
+
+	return r.changeReaction(ctx, id, userID, query, emoji)
+}
+
+// RemoveReaction takes back the reaction of userID and returns the
+// reactions left on the comment.
+func (r *Repository) RemoveReaction(ctx context.Context, id int, userID, emoji string) ([]model.Reaction, error) {
+	query := "DELETE FROM comment_reactions WHERE comment_id = $1 AND user_id = $2 AND emoji = $3"
+
+	return r.changeReaction(ctx, id, userID, query, emoji)
+}
+
+func (r *Repository) changeReaction(ctx context.Context, id int, userID, query, emoji string) ([]model.Reaction, error) {
+	tx, err := r.db.Master.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := lockLiveComment(ctx, tx, id); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.ExecContext(ctx, query, id, userID, emoji); err != nil {
+		return nil, fmt.Errorf("could not save reaction to db: %w", err)
+	}
+
+	reactions, err := queryReactions(ctx, tx, []int64{int64(id)}, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("could not commit transaction: %w", err)
+	}
+
+	if reactions[id] == nil {
+		return []model.Reaction{}, nil
+	}
+	return reactions[id], nil
+}
@@ -7,8 +7,11 @@ import (
 )
 
 var (
-	ErrNotSuchComment = errors.New("there is not comment with such id")
-	ErrInvalidParenID = errors.New("there is not parent comment with provided id")
+	ErrNotSuchComment  = errors.New("there is not comment with such id")
+	ErrInvalidParenID  = errors.New("there is not parent comment with provided id")
+	ErrNotAuthor       = errors.New("comment belongs to another author")
+	ErrNotInTrash      = errors.New("there is not deleted comment with such id")
+	ErrMoveIntoSubtree = errors.New("comment cannot be moved under itself or its replies")
 )
 
 type Repository struct {
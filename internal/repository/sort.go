@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"cmp"
+	"math"
+	"slices"
+
+	"github.com/Komilov31/comment-tree/internal/dto"
+	"github.com/Komilov31/comment-tree/internal/model"
+)
+
+// orderBy returns the ORDER BY list for comments aliased as "c" that
+// matches sortTree, so that pages are cut in the order they are shown.
+func orderBy(order dto.SortOrder) string {
+	switch order {
+	case dto.SortNewest:
+		return "c.created_at DESC, c.id DESC"
+	case dto.SortMostReplied:
+		return "c.reply_count DESC, c.descendant_count DESC, c.created_at ASC, c.id ASC"
+	case dto.SortTopVoted:
+		return "c.score DESC, c.upvotes DESC, c.created_at ASC, c.id ASC"
+	case dto.SortBest:
+		return "comments_wilson_lower_bound(c.upvotes, c.downvotes) DESC, c.score DESC, c.created_at ASC, c.id ASC"
+	default:
+		return "c.created_at ASC, c.id ASC"
+	}
+}
+
+// sortTree orders the roots and, level by level, the replies of every
+// comment in the tree.
+func sortTree(comments []*model.Comment, order dto.SortOrder) {
+	compare := compareComments(order)
+
+	var sortLevel func([]*model.Comment)
+	sortLevel = func(siblings []*model.Comment) {
+		slices.SortStableFunc(siblings, compare)
+		for _, comment := range siblings {
+			sortLevel(comment.Children)
+		}
+	}
+
+	sortLevel(comments)
+}
+
+func compareComments(order dto.SortOrder) func(a, b *model.Comment) int {
+	oldest := func(a, b *model.Comment) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	}
+
+	switch order {
+	case dto.SortNewest:
+		return func(a, b *model.Comment) int {
+			return oldest(b, a)
+		}
+	case dto.SortMostReplied:
+		return func(a, b *model.Comment) int {
+			if c := cmp.Compare(b.ReplyCount, a.ReplyCount); c != 0 {
+				return c
+			}
+			if c := cmp.Compare(b.DescendantCount, a.DescendantCount); c != 0 {
+				return c
+			}
+			return oldest(a, b)
+		}
+	case dto.SortTopVoted:
+		return func(a, b *model.Comment) int {
+			if c := cmp.Compare(b.Score, a.Score); c != 0 {
+				return c
+			}
+			if c := cmp.Compare(b.Upvotes, a.Upvotes); c != 0 {
+				return c
+			}
+			return oldest(a, b)
+		}
+	case dto.SortBest:
+		return func(a, b *model.Comment) int {
+			if c := cmp.Compare(wilsonLowerBound(b.Votes), wilsonLowerBound(a.Votes)); c != 0 {
+				return c
+			}
+			if c := cmp.Compare(b.Score, a.Score); c != 0 {
+				return c
+			}
+			return oldest(a, b)
+		}
+	default:
+		return oldest
+	}
+}
+
+// wilsonLowerBound mirrors the comments_wilson_lower_bound SQL function: the
+// lower bound of the 95% confidence interval of the share of upvotes.
+func wilsonLowerBound(votes model.Votes) float64 {
+	// without upvotes the bound is exactly zero, but the formula below
+	// leaves a rounding error that would rank downvoted comments above
+	// comments without votes; the SQL function has the same guard
+	if votes.Upvotes == 0 {
+		return 0
+	}
+
+	n := float64(votes.Upvotes + votes.Downvotes)
+
+	const z = 1.96
+	p := float64(votes.Upvotes) / n
+	return (p + z*z/(2*n) - z*math.Sqrt((p*(1-p)+z*z/(4*n))/n)) / (1 + z*z/n)
+}
@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Komilov31/comment-tree/internal/dto"
+	"github.com/Komilov31/comment-tree/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWilsonLowerBound(t *testing.T) {
+	// expected values of the 95% Wilson score interval, as computed by the
+	// comments_wilson_lower_bound SQL function
+	tests := []struct {
+		name      string
+		upvotes   int
+		downvotes int
+		want      float64
+	}{
+		{name: "no votes", upvotes: 0, downvotes: 0, want: 0},
+		{name: "one upvote", upvotes: 1, downvotes: 0, want: 0.206543},
+		{name: "all up", upvotes: 10, downvotes: 0, want: 0.722460},
+		{name: "many all up", upvotes: 100, downvotes: 0, want: 0.963005},
+		{name: "one downvote", upvotes: 0, downvotes: 1, want: 0},
+		{name: "two downvotes", upvotes: 0, downvotes: 2, want: 0},
+		{name: "all down", upvotes: 0, downvotes: 10, want: 0},
+		{name: "even", upvotes: 5, downvotes: 5, want: 0.236590},
+		{name: "mostly up", upvotes: 3, downvotes: 1, want: 0.300636},
+		{name: "mixed", upvotes: 60, downvotes: 40, want: 0.502001},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := wilsonLowerBound(model.Votes{Upvotes: tt.upvotes, Downvotes: tt.downvotes})
+			// a bound of zero must be exact to tie with comments without votes
+			if tt.want == 0 {
+				assert.Zero(t, got)
+				return
+			}
+			assert.InDelta(t, tt.want, got, 1e-6)
+		})
+	}
+}
+
+func TestWilsonLowerBound_NoUpvotesIsZero(t *testing.T) {
+	for downvotes := 0; downvotes <= 100; downvotes++ {
+		assert.Zero(t, wilsonLowerBound(model.Votes{Downvotes: downvotes}), downvotes)
+	}
+
+	// there is no database in unit tests, so the SQL function is checked
+	// for the same guard
+	assert.Contains(t, migratedFunction(t, "comments_wilson_lower_bound"), "CASE WHEN upvotes = 0 THEN 0")
+}
+
+func TestWilsonLowerBound_MoreVotesRankHigher(t *testing.T) {
+	// the same share of upvotes is more trustworthy with more votes
+	assert.Less(t, wilsonLowerBound(model.Votes{Upvotes: 1}), wilsonLowerBound(model.Votes{Upvotes: 10}))
+	assert.Less(t, wilsonLowerBound(model.Votes{Upvotes: 3, Downvotes: 1}), wilsonLowerBound(model.Votes{Upvotes: 30, Downvotes: 10}))
+}
+
+func TestSortTree_TieBreaking(t *testing.T) {
+	at := func(minute int) time.Time {
+		return time.Date(2025, 9, 20, 0, minute, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name     string
+		order    dto.SortOrder
+		comments []*model.Comment
+		want     []int
+	}{
+		{
+			name:  "oldest breaks equal time by id",
+			order: dto.SortOldest,
+			comments: []*model.Comment{
+				{ID: 3, CreatedAt: at(1)},
+				{ID: 2, CreatedAt: at(1)},
+				{ID: 1, CreatedAt: at(2)},
+			},
+			want: []int{2, 3, 1},
+		},
+		{
+			name:  "newest breaks equal time by id",
+			order: dto.SortNewest,
+			comments: []*model.Comment{
+				{ID: 2, CreatedAt: at(1)},
+				{ID: 3, CreatedAt: at(1)},
+				{ID: 1, CreatedAt: at(2)},
+			},
+			want: []int{1, 3, 2},
+		},
+		{
+			name:  "most replied breaks equal replies by descendants, then age",
+			order: dto.SortMostReplied,
+			comments: []*model.Comment{
+				{ID: 1, CreatedAt: at(1), ReplyCount: 2, DescendantCount: 2},
+				{ID: 2, CreatedAt: at(2), ReplyCount: 2, DescendantCount: 5},
+				{ID: 3, CreatedAt: at(3), ReplyCount: 2, DescendantCount: 2},
+				{ID: 4, CreatedAt: at(4), ReplyCount: 3, DescendantCount: 3},
+			},
+			want: []int{4, 2, 1, 3},
+		},
+		{
+			name:  "top voted breaks equal score by upvotes, then age",
+			order: dto.SortTopVoted,
+			comments: []*model.Comment{
+				{ID: 1, CreatedAt: at(1), Votes: model.Votes{Score: 1, Upvotes: 1}},
+				{ID: 2, CreatedAt: at(2), Votes: model.Votes{Score: 1, Upvotes: 3, Downvotes: 2}},
+				{ID: 3, CreatedAt: at(3), Votes: model.Votes{Score: 1, Upvotes: 1}},
+				{ID: 4, CreatedAt: at(4), Votes: model.Votes{Score: 2, Upvotes: 2}},
+			},
+			want: []int{4, 2, 1, 3},
+		},
+		{
+			name:  "best breaks equal bound by score, then age",
+			order: dto.SortBest,
+			comments: []*model.Comment{
+				// no votes and only downvotes share a bound of zero
+				{ID: 1, CreatedAt: at(1), Votes: model.Votes{Score: -2, Downvotes: 2}},
+				{ID: 2, CreatedAt: at(2)},
+				{ID: 3, CreatedAt: at(3)},
+				{ID: 4, CreatedAt: at(4), Votes: model.Votes{Score: 1, Upvotes: 1}},
+			},
+			want: []int{4, 2, 3, 1},
+		},
+		{
+			name:  "best prefers more votes with the same share",
+			order: dto.SortBest,
+			comments: []*model.Comment{
+				{ID: 1, CreatedAt: at(1), Votes: model.Votes{Score: 1, Upvotes: 1}},
+				{ID: 2, CreatedAt: at(2), Votes: model.Votes{Score: 5, Upvotes: 5}},
+			},
+			want: []int{2, 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sortTree(tt.comments, tt.order)
+
+			got := make([]int, len(tt.comments))
+			for i, comment := range tt.comments {
+				got[i] = comment.ID
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSortTree_SortsEveryLevel(t *testing.T) {
+	at := func(minute int) time.Time {
+		return time.Date(2025, 9, 20, 0, minute, 0, 0, time.UTC)
+	}
+
+	comments := []*model.Comment{
+		{ID: 1, CreatedAt: at(1), Children: []*model.Comment{
+			{ID: 3, CreatedAt: at(3)},
+			{ID: 4, CreatedAt: at(4), Children: []*model.Comment{
+				{ID: 5, CreatedAt: at(5)},
+				{ID: 6, CreatedAt: at(6)},
+			}},
+		}},
+		{ID: 2, CreatedAt: at(2)},
+	}
+
+	sortTree(comments, dto.SortNewest)
+
+	assert.Equal(t, 2, comments[0].ID)
+	assert.Equal(t, 4, comments[1].Children[0].ID)
+	assert.Equal(t, 6, comments[1].Children[0].Children[0].ID)
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Komilov31/comment-tree/internal/dto"
+	"github.com/Komilov31/comment-tree/internal/model"
+)
+
+// suggestTermsScope is the number of the newest matching comments whose
+// lexemes are counted for term suggestions, which keeps them fast on
+// popular prefixes.
+const suggestTermsScope = 200
+
+// suggestExcerptOptions keep suggestion excerpts down to a few words around
+// the match.
+const suggestExcerptOptions = "MaxWords=12, MinWords=4"
+
+// SuggestComments returns excerpts of the live comments of a thread that
+// best match the prefix tsquery suggest.TSQuery and the lexemes starting
+// with the normalised suggest.Prefix that are the most frequent among the
+// matches.
+func (r *Repository) SuggestComments(ctx context.Context, suggest dto.Suggest) (*model.Suggestions, error) {
+	matches, args := textMatches("to_tsquery", suggest.SearchConfigs, []any{suggest.TSQuery, suggest.ThreadKey})
+	if len(matches) == 0 {
+		matches = append(matches, "FALSE")
+	}
+	filter := `c.thread_key = $2 AND c.deleted_at IS NULL
+	AND (` + strings.Join(matches, " OR ") + `)`
+
+	suggestions := &model.Suggestions{
+		Comments: []model.Suggestion{},
+		Terms:    []string{},
+	}
+
+	commentArgs := append(slices.Clip(args), suggestExcerptOptions, suggest.Limit)
+	query := fmt.Sprintf(`SELECT c.id, ts_headline(c.search_config, COALESCE(c.text, ''), to_tsquery(c.search_config, $1), $%d)
+	FROM comments c
+	WHERE %s
+	ORDER BY ts_rank(c.search_vector, to_tsquery(c.search_config, $1)) DESC, c.id DESC
+	LIMIT $%d`, len(args)+1, filter, len(args)+2)
+
+	rows, err := r.db.Master.QueryContext(ctx, query, commentArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("could not get suggested comments from db: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var suggestion model.Suggestion
+		if err := rows.Scan(&suggestion.ID, &suggestion.Excerpt); err != nil {
+			return nil, fmt.Errorf("could not scan row to model: %w", err)
+		}
+		suggestions.Comments = append(suggestions.Comments, suggestion)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not read rows from db: %w", err)
+	}
+
+	if len(suggestions.Comments) == 0 {
+		return suggestions, nil
+	}
+
+	termArgs := append(slices.Clip(args), suggestTermsScope, suggest.Prefix, suggest.Limit)
+	rows, err = r.db.Master.QueryContext(ctx, suggestTermsQuery(filter, len(args)), termArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("could not get suggested terms from db: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var term string
+		if err := rows.Scan(&term); err != nil {
+			return nil, fmt.Errorf("could not scan row to model: %w", err)
+		}
+		suggestions.Terms = append(suggestions.Terms, term)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not read rows from db: %w", err)
+	}
+
+	return suggestions, nil
+}
+
+// suggestTermsQuery returns the query for the most frequent lexemes of the
+// comments matching filter that start with a prefix. The parameters after
+// the nargs ones of filter are the number of comments to look at, the
+// prefix and the number of lexemes. The lexemes are stemmed, so the prefix
+// is normalised with the configuration of each comment before it is
+// compared with them: "running" matches "run".
+func suggestTermsQuery(filter string, nargs int) string {
+	return fmt.Sprintf(`SELECT t.lexeme FROM (
+		SELECT c.search_vector, c.search_config FROM comments c
+		WHERE %s
+		ORDER BY c.id DESC
+		LIMIT $%d
+	) m, unnest(m.search_vector) t
+	WHERE array_to_tsvector(ARRAY[t.lexeme]) @@ to_tsquery(m.search_config, $%d || ':*')
+	GROUP BY t.lexeme
+	ORDER BY COUNT(*) DESC, t.lexeme ASC
+	LIMIT $%d`, filter, nargs+1, nargs+2, nargs+3)
+}
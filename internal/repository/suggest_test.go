@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSuggestTermsQuery_NormalisesPrefix(t *testing.T) {
+	query := suggestTermsQuery("c.thread_key = $2", 3)
+
+	// the prefix is stemmed with the configuration of each comment, as its
+	// lexemes are, instead of being compared with them as typed
+	assert.Contains(t, query, "to_tsquery(m.search_config, $5 || ':*')")
+	assert.Contains(t, query, "SELECT c.search_vector, c.search_config FROM comments c")
+	assert.NotContains(t, query, "starts_with")
+	assert.Contains(t, query, "LIMIT $4")
+	assert.Contains(t, query, "LIMIT $6")
+}
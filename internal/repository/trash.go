@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Komilov31/comment-tree/internal/dto"
+)
+
+// RestoreCommentById undoes the deletion of a comment: a soft-deleted
+// tombstone becomes a live comment again, and a subtree deleted together
+// with its replies is moved back from the trash.
+func (r *Repository) RestoreCommentById(ctx context.Context, id int, requester dto.Requester) error {
+	tx, err := r.db.Master.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("could not begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	restored, err := restoreTombstone(ctx, tx, id, requester)
+	if err != nil {
+		return err
+	}
+	if restored {
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("could not commit transaction: %w", err)
+		}
+		return nil
+	}
+
+	var owner sql.NullString
+	query := `SELECT author_id FROM comments_trash
+	WHERE id = $1 AND trash_root_id = $1
+	FOR UPDATE`
+
+	err = tx.QueryRowContext(ctx, query, id).Scan(&owner)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotInTrash
+		}
+		return fmt.Errorf("could not get comment from trash: %w", err)
+	}
+
+	if !requester.Admin && (!owner.Valid || owner.String != requester.ID) {
+		return ErrNotAuthor
+	}
+
+	query = `INSERT INTO comments(id, parent_id, thread_key, author_id, text, language, search_config, created_at, edited_at, deleted_at)
+	SELECT id, parent_id, thread_key, author_id, text, language, search_config, created_at, edited_at, deleted_at
+	FROM comments_trash
+	WHERE trash_root_id = $1`
+
+	if _, err := tx.ExecContext(ctx, query, id); err != nil {
+		if isForeignKeyViolation(err) {
+			return ErrInvalidParenID
+		}
+		return fmt.Errorf("could not restore comments from trash: %w", err)
+	}
+
+	// the rows above are inserted in no particular order, so the paths set
+	// by the insert trigger may miss parents restored after their replies
+	if _, err := tx.ExecContext(ctx, "SELECT comments_rebuild_paths($1)", id); err != nil {
+		return fmt.Errorf("could not rebuild paths of restored comments: %w", err)
+	}
+
+	query = `INSERT INTO comment_revisions(id, comment_id, text, created_at, replaced_at)
+	SELECT r.id, r.comment_id, r.text, r.created_at, r.replaced_at
+	FROM comment_revisions_trash r
+	INNER JOIN comments_trash t ON t.id = r.comment_id
+	WHERE t.trash_root_id = $1`
+
+	if _, err := tx.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("could not restore comment revisions from trash: %w", err)
+	}
+
+	query = `INSERT INTO comment_votes(comment_id, user_id, value, created_at, updated_at)
+	SELECT v.comment_id, v.user_id, v.value, v.created_at, v.updated_at
+	FROM comment_votes_trash v
+	INNER JOIN comments_trash t ON t.id = v.comment_id
+	WHERE t.trash_root_id = $1`
+
+	if _, err := tx.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("could not restore comment votes from trash: %w", err)
+	}
+
+	query = `INSERT INTO comment_reactions(comment_id, user_id, emoji, created_at)
+	SELECT r.comment_id, r.user_id, r.emoji, r.created_at
+	FROM comment_reactions_trash r
+	INNER JOIN comments_trash t ON t.id = r.comment_id
+	WHERE t.trash_root_id = $1`
+
+	if _, err := tx.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("could not restore comment reactions from trash: %w", err)
+	}
+
+	query = "DELETE FROM comments_trash WHERE trash_root_id = $1"
+	if _, err := tx.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("could not delete comments from trash: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("could not commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// restoreTombstone clears deleted_at of the soft-deleted comment id and
+// reports whether there was one.
+func restoreTombstone(ctx context.Context, tx *sql.Tx, id int, requester dto.Requester) (bool, error) {
+	var owner sql.NullString
+	query := `SELECT author_id FROM comments
+	WHERE id = $1 AND deleted_at IS NOT NULL
+	FOR UPDATE`
+
+	err := tx.QueryRowContext(ctx, query, id).Scan(&owner)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, fmt.Errorf("could not get deleted comment from db: %w", err)
+	}
+
+	if !requester.Admin && (!owner.Valid || owner.String != requester.ID) {
+		return false, ErrNotAuthor
+	}
+
+	query = "UPDATE comments SET deleted_at = NULL WHERE id = $1"
+	if _, err := tx.ExecContext(ctx, query, id); err != nil {
+		return false, fmt.Errorf("could not restore deleted comment in db: %w", err)
+	}
+
+	return true, nil
+}
+
+// PurgeTrash permanently removes subtrees trashed before the given time and
+// returns the number of removed comments.
+func (r *Repository) PurgeTrash(ctx context.Context, trashedBefore time.Time) (int64, error) {
+	query := "DELETE FROM comments_trash WHERE trashed_at < $1"
+
+	result, err := r.db.Master.ExecContext(ctx, query, trashedBefore)
+	if err != nil {
+		return 0, fmt.Errorf("could not purge trash: %w", err)
+	}
+
+	purged, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("could not count purged comments: %w", err)
+	}
+
+	return purged, nil
+}
+
+// moveSubtreeToTrash copies the comment, all its replies and their
+// revisions, votes and reactions into the trash tables. The caller deletes the originals.
+func moveSubtreeToTrash(ctx context.Context, tx *sql.Tx, id int) error {
+	query := `INSERT INTO comments_trash(id, parent_id, thread_key, author_id, text, language, search_config, created_at, edited_at, deleted_at, trash_root_id)
+	SELECT c.id, c.parent_id, c.thread_key, c.author_id, c.text, c.language, c.search_config, c.created_at, c.edited_at, c.deleted_at, $1
+	FROM comments c
+	WHERE c.path <@ (SELECT path FROM comments WHERE id = $1)`
+
+	if _, err := tx.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("could not move comments to trash: %w", err)
+	}
+
+	query = `INSERT INTO comment_revisions_trash(id, comment_id, text, created_at, replaced_at)
+	SELECT r.id, r.comment_id, r.text, r.created_at, r.replaced_at
+	FROM comment_revisions r
+	INNER JOIN comments_trash t ON t.id = r.comment_id
+	WHERE t.trash_root_id = $1`
+
+	if _, err := tx.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("could not move comment revisions to trash: %w", err)
+	}
+
+	query = `INSERT INTO comment_votes_trash(comment_id, user_id, value, created_at, updated_at)
+	SELECT v.comment_id, v.user_id, v.value, v.created_at, v.updated_at
+	FROM comment_votes v
+	INNER JOIN comments_trash t ON t.id = v.comment_id
+	WHERE t.trash_root_id = $1`
+
+	if _, err := tx.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("could not move comment votes to trash: %w", err)
+	}
+
+	query = `INSERT INTO comment_reactions_trash(comment_id, user_id, emoji, created_at)
+	SELECT r.comment_id, r.user_id, r.emoji, r.created_at
+	FROM comment_reactions r
+	INNER JOIN comments_trash t ON t.id = r.comment_id
+	WHERE t.trash_root_id = $1`
+
+	if _, err := tx.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("could not move comment reactions to trash: %w", err)
+	}
+
+	return nil
+}
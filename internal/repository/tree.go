@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Komilov31/comment-tree/internal/dto"
+	"github.com/Komilov31/comment-tree/internal/model"
+)
+
+// GetCommentsTree returns comment config.ParentID with its replies, going
+// at most config.MaxDepth levels down and loading at most
+// config.MaxChildren replies of every comment (zero means no limit). With
+// config.After only the direct replies following that cursor are loaded.
+// Comments with replies left behind get a MoreReplies marker.
+//
+// Replies are loaded newest first for dto.SortNewest and oldest first
+// otherwise, so that the cursors stay valid. With config.MaxChildren the
+// caller must pass a chronological config.Sort, otherwise config.Sort
+// orders the loaded replies.
+func (r *Repository) GetCommentsTree(ctx context.Context, config dto.CommentsPagination) ([]*model.Comment, error) {
+	args := []any{config.ParentID, config.ThreadKey, config.MaxDepth}
+
+	order, compare, skippedCompare := dto.SortOldest, ">", "<="
+	if config.Sort == dto.SortNewest {
+		order, compare, skippedCompare = dto.SortNewest, "<", ">="
+	}
+
+	skipped := "0"
+	if config.After != nil {
+		args = append(args, config.After.CreatedAt, config.After.ID)
+		skipped = `(SELECT COUNT(*) FROM comments ch
+		WHERE c.id = $1 AND ch.parent_id = c.id AND (ch.created_at, ch.id) ` + skippedCompare + ` ($4, $5))`
+	}
+
+	var query string
+	if config.MaxChildren == 0 {
+		// without a limit per comment the whole subtree down to the depth
+		// limit is a range of the materialized path
+		afterFilter := ""
+		if config.After != nil {
+			afterFilter = `
+		AND NOT EXISTS (
+			SELECT 1 FROM comments s
+			WHERE s.parent_id = $1 AND (s.created_at, s.id) ` + skippedCompare + ` ($4, $5) AND c.path <@ s.path
+		)`
+		}
+
+		query = `SELECT ` + commentColumns + `, ` + skipped + `
+		FROM comments c
+		INNER JOIN comments root ON root.id = $1 AND root.thread_key = $2
+		LEFT JOIN authors a ON a.id = c.author_id
+		WHERE c.path <@ root.path
+		AND ($3 = 0 OR c.depth <= root.depth + $3)` + afterFilter + `
+		ORDER BY c.depth ASC, ` + orderBy(order) + `;`
+	} else {
+		// the replies of every comment are limited separately, so the tree
+		// is loaded level by level
+		args = append(args, config.MaxChildren)
+		limit := fmt.Sprintf("$%d", len(args))
+
+		afterFilter := ""
+		if config.After != nil {
+			afterFilter = " AND (c.parent_id <> $1 OR (c.created_at, c.id) " + compare + " ($4, $5))"
+		}
+
+		query = `WITH RECURSIVE comment_tree AS (
+		SELECT * FROM comments
+		WHERE id = $1 AND thread_key = $2
+
+		UNION ALL
+
+		SELECT ch.*
+		FROM comment_tree ct
+		CROSS JOIN LATERAL (
+			SELECT c.* FROM comments c
+			WHERE c.parent_id = ct.id` + afterFilter + `
+			ORDER BY ` + orderBy(order) + `
+			LIMIT ` + limit + `
+		) ch
+		WHERE $3 = 0 OR ct.depth < (SELECT depth FROM comments WHERE id = $1) + $3
+		)
+		SELECT ` + commentColumns + `, ` + skipped + `
+		FROM comment_tree c
+		LEFT JOIN authors a ON a.id = c.author_id
+		ORDER BY c.depth ASC, ` + orderBy(order) + `;`
+	}
+
+	rows, err := r.db.Master.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("could not get comments from db: %w", err)
+	}
+	defer rows.Close()
+
+	var comments []model.Comment
+	var skippedCounts []int
+	for rows.Next() {
+		var comment model.Comment
+		var skippedCount int
+		if err := scanComment(rows, &comment, &skippedCount); err != nil {
+			return nil, err
+		}
+
+		comments = append(comments, comment)
+		skippedCounts = append(skippedCounts, skippedCount)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not read rows from db: %w", err)
+	}
+
+	if len(comments) == 0 {
+		return nil, ErrNotSuchComment
+	}
+
+	if err := r.loadCommentDetails(ctx, comments, config.ViewerID); err != nil {
+		return nil, err
+	}
+
+	commentTree := buildTree(comments)
+
+	for i := range comments {
+		comment := &comments[i]
+		remaining := comment.ReplyCount - skippedCounts[i] - len(comment.Children)
+		if remaining <= 0 {
+			continue
+		}
+
+		comment.More = &model.MoreReplies{Remaining: remaining}
+		if n := len(comment.Children); n > 0 {
+			last := comment.Children[n-1]
+			after := dto.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}.Encode()
+			comment.More.After = &after
+		}
+	}
+
+	sortTree(commentTree, config.Sort)
+
+	return commentTree, nil
+}
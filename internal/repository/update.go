@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Komilov31/comment-tree/internal/model"
+)
+
+func (r *Repository) UpdateComment(ctx context.Context, id int, authorID string, text string) (*model.Comment, error) {
+	tx, err := r.db.Master.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var oldText string
+	var owner sql.NullString
+	var versionCreatedAt time.Time
+	query := `SELECT COALESCE(text, ''), author_id, COALESCE(edited_at, created_at)
+	FROM comments
+	WHERE id = $1 AND deleted_at IS NULL
+	FOR UPDATE`
+
+	err = tx.QueryRowContext(ctx, query, id).Scan(&oldText, &owner, &versionCreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotSuchComment
+		}
+		return nil, fmt.Errorf("could not get comment from db: %w", err)
+	}
+
+	if !owner.Valid || owner.String != authorID {
+		return nil, ErrNotAuthor
+	}
+
+	if oldText != text {
+		query = `INSERT INTO comment_revisions(comment_id, text, created_at)
+		VALUES ($1, $2, $3)`
+
+		if _, err := tx.ExecContext(ctx, query, id, oldText, versionCreatedAt); err != nil {
+			return nil, fmt.Errorf("could not save comment revision in db: %w", err)
+		}
+
+		query = `UPDATE comments SET text = $2, edited_at = CURRENT_TIMESTAMP WHERE id = $1`
+		if _, err := tx.ExecContext(ctx, query, id, text); err != nil {
+			return nil, fmt.Errorf("could not update comment in db: %w", err)
+		}
+	}
+
+	query = `SELECT ` + commentColumns + ` FROM comments c
+	LEFT JOIN authors a ON a.id = c.author_id
+	WHERE c.id = $1`
+
+	rows, err := tx.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("could not get comment from db: %w", err)
+	}
+	comments, err := scanComments(rows)
+	rows.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("could not commit transaction: %w", err)
+	}
+
+	if err := r.loadCommentDetails(ctx, comments, authorID); err != nil {
+		return nil, err
+	}
+
+	return &comments[0], nil
+}
+
+func (r *Repository) GetCommentRevisions(ctx context.Context, id int) ([]model.Revision, error) {
+	// previous versions followed by the current one
+	query := `SELECT COALESCE(text, ''), created_at FROM (
+		SELECT text, created_at, FALSE AS is_current, id AS ord FROM comment_revisions WHERE comment_id = $1
+		UNION ALL
+		SELECT text, COALESCE(edited_at, created_at), TRUE, NULL FROM comments WHERE id = $1
+	) versions
+	WHERE EXISTS (SELECT 1 FROM comments WHERE id = $1 AND deleted_at IS NULL)
+	ORDER BY is_current, ord`
+
+	rows, err := r.db.Master.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("could not get comment revisions from db: %w", err)
+	}
+	defer rows.Close()
+
+	var revisions []model.Revision
+	for rows.Next() {
+		var revision model.Revision
+		if err := rows.Scan(&revision.Text, &revision.CreatedAt); err != nil {
+			return nil, fmt.Errorf("could not scan row to model: %w", err)
+		}
+
+		revision.Version = len(revisions) + 1
+		revisions = append(revisions, revision)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not read rows from db: %w", err)
+	}
+
+	if len(revisions) == 0 {
+		return nil, ErrNotSuchComment
+	}
+
+	return revisions, nil
+}
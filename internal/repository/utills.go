@@ -1,35 +1,252 @@
 package repository
 
 import (
+	"context"
+	"database/sql"
 	"errors"
+	"fmt"
+	"strings"
+	"time"
 
+	"github.com/Komilov31/comment-tree/internal/dto"
 	"github.com/Komilov31/comment-tree/internal/model"
 	"github.com/lib/pq"
 )
 
+// tombstoneText replaces the text of soft deleted comments in every read.
+const tombstoneText = "[deleted]"
+
+// commentColumns is the select list expected by scanComments. Queries alias
+// the comments source as "c" and the joined authors table as "a".
+const commentColumns = `c.id, c.parent_id, c.thread_key, c.depth, c.language, COALESCE(c.text, ''), c.created_at, c.edited_at, c.deleted_at,
+	c.reply_count, c.descendant_count, c.last_reply_at, c.score, c.upvotes, c.downvotes,
+	a.id, a.display_name, a.avatar_url`
+
+// scanComments reads rows selected with commentColumns. Columns selected
+// after them are scanned into extra, which ends up holding the values of
+// the last row.
+func scanComments(rows *sql.Rows, extra ...any) ([]model.Comment, error) {
+	var comments []model.Comment
+	for rows.Next() {
+		var comment model.Comment
+		if err := scanComment(rows, &comment, extra...); err != nil {
+			return nil, err
+		}
+
+		comments = append(comments, comment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("could not read rows from db: %w", err)
+	}
+
+	return comments, nil
+}
+
+// scanComment reads the current row into comment, hiding the text and
+// author of soft deleted comments.
+func scanComment(rows *sql.Rows, comment *model.Comment, extra ...any) error {
+	var deletedAt *time.Time
+	var authorID, authorName, authorAvatar sql.NullString
+	dest := []any{
+		&comment.ID,
+		&comment.ParentID,
+		&comment.ThreadKey,
+		&comment.Depth,
+		&comment.Language,
+		&comment.Text,
+		&comment.CreatedAt,
+		&comment.EditedAt,
+		&deletedAt,
+		&comment.ReplyCount,
+		&comment.DescendantCount,
+		&comment.LastReplyAt,
+		&comment.Score,
+		&comment.Upvotes,
+		&comment.Downvotes,
+		&authorID,
+		&authorName,
+		&authorAvatar,
+	}
+	if err := rows.Scan(append(dest, extra...)...); err != nil {
+		return fmt.Errorf("could not scan row to model: %w", err)
+	}
+	comment.ChildrenCount = comment.ReplyCount
+
+	if deletedAt != nil {
+		comment.Deleted = true
+		comment.Text = tombstoneText
+		comment.EditedAt = nil
+	} else if authorID.Valid {
+		comment.Author = &model.Author{
+			ID:          authorID.String,
+			DisplayName: authorName.String,
+		}
+		if authorAvatar.Valid {
+			comment.Author.AvatarURL = &authorAvatar.String
+		}
+	}
+
+	return nil
+}
+
+// commentsQuery selects columns from the subtree of config.ParentID, or
+// from the whole thread when it is zero. Callers append their own
+// conditions with AND, ordering and placeholders numbered after args.
+func commentsQuery(config dto.CommentsPagination, columns string) (string, []any) {
+	if config.ParentID != 0 {
+		return `SELECT ` + columns + ` FROM comments c
+	LEFT JOIN authors a ON a.id = c.author_id
+	WHERE ` + subtreeFilter, []any{config.ParentID, config.ThreadKey}
+	}
+
+	return `SELECT ` + columns + ` FROM comments c
+	LEFT JOIN authors a ON a.id = c.author_id
+	WHERE c.thread_key = $1`, []any{config.ThreadKey}
+}
+
+// searchFilter matches the live comments of the thread of search in any of
+// search.SearchConfigs with its text, bound to $1, and applies the optional
+// filters of search. Full-text matches parse the text with the text search
+// configuration of each comment, fuzzy matches compare trigrams against the
+// pg_trgm.word_similarity_threshold of the transaction.
+func searchFilter(search dto.SearchText) (string, []any) {
+	args := []any{search.Text, search.ThreadKey}
+	conditions := []string{"c.thread_key = $2", "c.deleted_at IS NULL"}
+
+	var matches []string
+	if search.Mode != dto.SearchFuzzy {
+		matches, args = textMatches("websearch_to_tsquery", search.SearchConfigs, args)
+	}
+	if isFuzzySearch(search.Mode) && len(search.SearchConfigs) > 0 {
+		args = append(args, pq.Array(search.SearchConfigs))
+		matches = append(matches, fmt.Sprintf("(c.search_config = ANY($%d::regconfig[]) AND $1 <%% c.text)", len(args)))
+	}
+	if len(matches) == 0 {
+		matches = append(matches, "FALSE")
+	}
+	conditions = append(conditions, "("+strings.Join(matches, " OR ")+")")
+
+	if search.From != nil {
+		args = append(args, *search.From)
+		conditions = append(conditions, fmt.Sprintf("c.created_at >= $%d", len(args)))
+	}
+	if search.To != nil {
+		args = append(args, *search.To)
+		conditions = append(conditions, fmt.Sprintf("c.created_at <= $%d", len(args)))
+	}
+	if search.AuthorID != "" {
+		args = append(args, search.AuthorID)
+		conditions = append(conditions, fmt.Sprintf("c.author_id = $%d", len(args)))
+	}
+	if search.MinScore != nil {
+		args = append(args, *search.MinScore)
+		conditions = append(conditions, fmt.Sprintf("c.score >= $%d", len(args)))
+	}
+	if search.RootsOnly {
+		conditions = append(conditions, "c.parent_id IS NULL")
+	}
+
+	return strings.Join(conditions, "\n\tAND "), args
+}
+
+// textMatches matches the search vector of a comment with $1 parsed by
+// function with the text search configuration of the comment, one match
+// for each of configs. The configs are added to args.
+func textMatches(function string, configs []string, args []any) ([]string, []any) {
+	matches := make([]string, 0, len(configs)+1)
+	for _, config := range configs {
+		args = append(args, config)
+		matches = append(matches, fmt.Sprintf(
+			"(c.search_config = $%[1]d::regconfig AND c.search_vector @@ %[2]s($%[1]d::regconfig, $1))",
+			len(args), function,
+		))
+	}
+	return matches, args
+}
+
+// searchRank is the relevance of a search hit for mode: the ts_rank of its
+// search vector, the word similarity of its text or their sum.
+func searchRank(mode dto.SearchMode) string {
+	const (
+		fullTextRank = "ts_rank(c.search_vector, websearch_to_tsquery(c.search_config, $1))"
+		fuzzyRank    = "word_similarity($1, COALESCE(c.text, ''))"
+	)
+
+	switch mode {
+	case dto.SearchFuzzy:
+		return fuzzyRank
+	case dto.SearchBoth:
+		return "(" + fullTextRank + " + " + fuzzyRank + ")"
+	default:
+		return fullTextRank
+	}
+}
+
+func isFuzzySearch(mode dto.SearchMode) bool {
+	return mode == dto.SearchFuzzy || mode == dto.SearchBoth
+}
+
+// newOffsetPage builds the envelope of an offset page of comments, shown
+// as items. A zero limit means that everything was returned on the first
+// page.
+func newOffsetPage(comments []model.Comment, items []*model.Comment, page, limit, total int) *model.CommentsPage {
+	return &model.CommentsPage{
+		Items:      items,
+		Page:       page,
+		Limit:      limit,
+		TotalCount: &total,
+		HasMore:    limit > 0 && (page-1)*limit+len(comments) < total,
+	}
+}
+
 func buildTree(comments []model.Comment) []*model.Comment {
 	commentMap := make(map[int]*model.Comment)
-	var roots []*model.Comment
+	roots := []*model.Comment{}
 
 	for i := range comments {
 		commentMap[comments[i].ID] = &comments[i]
 	}
 
+	// comments whose parent is not part of the result (subtree roots and
+	// pages) are returned as roots
 	for i := range comments {
 		c := &comments[i]
 		if c.ParentID == nil {
 			roots = append(roots, c)
+			continue
+		}
+
+		parent, ok := commentMap[*c.ParentID]
+		if ok {
+			parent.Children = append(parent.Children, c)
 		} else {
-			parent, ok := commentMap[*c.ParentID]
-			if ok {
-				parent.Children = append(parent.Children, c)
-			}
+			roots = append(roots, c)
 		}
 	}
 
 	return roots
 }
 
+// lockLiveComment keeps the comment from being deleted until tx ends and
+// returns ErrNotSuchComment when it does not exist or is soft deleted.
+func lockLiveComment(ctx context.Context, tx *sql.Tx, id int) error {
+	var deleted bool
+	query := "SELECT deleted_at IS NOT NULL FROM comments WHERE id = $1 FOR SHARE"
+	if err := tx.QueryRowContext(ctx, query, id).Scan(&deleted); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotSuchComment
+		}
+		return fmt.Errorf("could not get comment from db: %w", err)
+	}
+
+	if deleted {
+		return ErrNotSuchComment
+	}
+
+	return nil
+}
+
 func isForeignKeyViolation(err error) bool {
 	var pqErr *pq.Error
 	if errors.As(err, &pqErr) {
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Komilov31/comment-tree/internal/model"
+)
+
+// Vote records the vote of userID on a comment, replacing their previous
+// vote, or removes it when value is zero. The tally is kept in comments by
+// a trigger on comment_votes.
+func (r *Repository) Vote(ctx context.Context, id int, userID string, value int) (*model.Votes, error) {
+	tx, err := r.db.Master.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := lockLiveComment(ctx, tx, id); err != nil {
+		return nil, err
+	}
+
+	var query string
+	if value == 0 {
+		query = "DELETE FROM comment_votes WHERE comment_id = $1 AND user_id = $2"
+		_, err = tx.ExecContext(ctx, query, id, userID)
+	} else {
+		query = `INSERT INTO comment_votes(comment_id, user_id, value)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (comment_id, user_id) DO UPDATE
+		SET value = EXCLUDED.value, updated_at = CURRENT_TIMESTAMP
+		WHERE comment_votes.value <> EXCLUDED.value`
+		_, err = tx.ExecContext(ctx, query, id, userID, value)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not save vote to db: %w", err)
+	}
+
+	votes := model.Votes{MyVote: value}
+	query = "SELECT score, upvotes, downvotes FROM comments WHERE id = $1"
+	if err := tx.QueryRowContext(ctx, query, id).Scan(&votes.Score, &votes.Upvotes, &votes.Downvotes); err != nil {
+		return nil, fmt.Errorf("could not get votes from db: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("could not commit transaction: %w", err)
+	}
+
+	return &votes, nil
+}
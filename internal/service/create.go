@@ -1,9 +1,18 @@
 package service
 
 import (
+	"context"
+
 	"github.com/Komilov31/comment-tree/internal/dto"
 )
 
-func (s *Service) CreateComment(comment dto.CreateComment) (*dto.CreateComment, error) {
-	return s.storage.CreateComment(comment)
+func (s *Service) CreateComment(ctx context.Context, comment dto.CreateComment) (*dto.CreateComment, error) {
+	language, searchConfig, err := s.languages.resolve(comment.Language, comment.Text)
+	if err != nil {
+		return nil, err
+	}
+	comment.Language = language
+	comment.SearchConfig = searchConfig
+
+	return s.storage.CreateComment(ctx, comment)
 }
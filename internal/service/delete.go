@@ -1,5 +1,21 @@
 package service
 
-func (s *Service) DeleteCommentById(id int) error {
-	return s.storage.DeleteCommentById(id)
+import (
+	"context"
+
+	"github.com/Komilov31/comment-tree/internal/dto"
+)
+
+// DeleteCommentById deletes the comment as configured by the delete mode.
+// Only admins may take the replies of other users along, so the comments
+// of other requesters are always soft deleted.
+func (s *Service) DeleteCommentById(ctx context.Context, id int, requester dto.Requester) error {
+	if s.deleteMode == DeleteModeHard && requester.Admin {
+		return s.storage.DeleteCommentById(ctx, id, requester)
+	}
+	return s.storage.SoftDeleteCommentById(ctx, id, requester)
+}
+
+func (s *Service) HardDeleteCommentById(ctx context.Context, id int, requester dto.Requester) error {
+	return s.storage.DeleteCommentById(ctx, id, requester)
 }
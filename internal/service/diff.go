@@ -0,0 +1,68 @@
+package service
+
+import (
+	"strings"
+
+	"github.com/Komilov31/comment-tree/internal/model"
+)
+
+const (
+	diffEqual  = "equal"
+	diffInsert = "insert"
+	diffDelete = "delete"
+)
+
+// diffWords returns a word level diff between two texts built from their
+// longest common subsequence. Adjacent words with the same operation are
+// merged into one op.
+func diffWords(from, to string) []model.DiffOp {
+	a := strings.Fields(from)
+	b := strings.Fields(to)
+
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var ops []model.DiffOp
+	add := func(op, word string) {
+		if n := len(ops); n > 0 && ops[n-1].Op == op {
+			ops[n-1].Text += " " + word
+			return
+		}
+		ops = append(ops, model.DiffOp{Op: op, Text: word})
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			add(diffEqual, a[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			add(diffDelete, a[i])
+			i++
+		default:
+			add(diffInsert, b[j])
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		add(diffDelete, a[i])
+	}
+	for ; j < len(b); j++ {
+		add(diffInsert, b[j])
+	}
+
+	return ops
+}
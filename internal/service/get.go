@@ -1,22 +1,62 @@
 package service
 
 import (
+	"context"
+
 	"github.com/Komilov31/comment-tree/internal/dto"
 	"github.com/Komilov31/comment-tree/internal/model"
 )
 
-func (s *Service) GetAllComments() ([]*model.Comment, error) {
-	return s.storage.GetAllComments()
+func (s *Service) GetAllComments(ctx context.Context, config dto.CommentsPagination) (*model.CommentsPage, error) {
+	return s.storage.GetAllComments(ctx, config)
+}
+
+func (s *Service) GetCommentById(ctx context.Context, id int, viewerID string) (*model.Comment, error) {
+	return s.storage.GetCommentById(ctx, id, viewerID)
+}
+
+func (s *Service) GetCommentAncestors(ctx context.Context, id int, viewerID string) ([]model.Comment, error) {
+	return s.storage.GetCommentAncestors(ctx, id, viewerID)
+}
+
+func (s *Service) GetCommentContext(ctx context.Context, config dto.CommentContext) ([]*model.Comment, error) {
+	return s.storage.GetCommentContext(ctx, config)
 }
 
-func (s *Service) GetCommentsById(id int) ([]*model.Comment, error) {
-	return s.storage.GetCommentsById(id)
+func (s *Service) GetCommentsById(ctx context.Context, config dto.CommentsPagination) ([]*model.Comment, error) {
+	return s.storage.GetCommentsById(ctx, config)
 }
 
-func (s *Service) GetCommentsPaginated(config dto.CommentsPagination) ([]*model.Comment, error) {
-	return s.storage.GetCommentsPaginated(config)
+func (s *Service) GetCommentsPaginated(ctx context.Context, config dto.CommentsPagination) (*model.CommentsPage, error) {
+	return s.storage.GetCommentsPaginated(ctx, config)
 }
 
-func (s *Service) GetCommentsByTextSearch(text string) ([]*model.Comment, error) {
-	return s.storage.GetCommentsByTextSearch(text)
+func (s *Service) GetCommentsByCursor(ctx context.Context, config dto.CommentsPagination) (*model.CommentsPage, error) {
+	return s.storage.GetCommentsByCursor(ctx, config)
+}
+
+func (s *Service) GetCommentsTree(ctx context.Context, config dto.CommentsPagination) ([]*model.Comment, error) {
+	return s.storage.GetCommentsTree(ctx, config)
+}
+
+func (s *Service) GetCommentsByTextSearch(ctx context.Context, search dto.SearchText) (*model.CommentsPage, error) {
+	if err := search.ValidateFilters(); err != nil {
+		return nil, err
+	}
+
+	searchConfigs, err := s.languages.searchConfigs(search.Lang)
+	if err != nil {
+		return nil, err
+	}
+	search.SearchConfigs = searchConfigs
+
+	if search.Highlight != nil {
+		options, err := search.Highlight.Options()
+		if err != nil {
+			return nil, err
+		}
+		search.HighlightOptions = options
+	}
+
+	return s.storage.GetCommentsByTextSearch(ctx, search)
 }
@@ -0,0 +1,28 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var ErrMigrationsBehind = errors.New("database migrations are behind")
+
+// CheckReadiness returns the migration version of the database, with an
+// error when the database is unreachable or not migrated up to
+// expectedMigration yet.
+func (s *Service) CheckReadiness(ctx context.Context, expectedMigration int64) (int64, error) {
+	if err := s.storage.Ping(ctx); err != nil {
+		return 0, err
+	}
+
+	version, err := s.storage.MigrationVersion(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	if version < expectedMigration {
+		return version, fmt.Errorf("%w: at %d, expected %d", ErrMigrationsBehind, version, expectedMigration)
+	}
+	return version, nil
+}
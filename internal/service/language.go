@@ -0,0 +1,99 @@
+package service
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/Komilov31/comment-tree/internal/dto"
+)
+
+// Languages maps the language codes of comments to the Postgres text
+// search configurations their search vectors are built with.
+type Languages struct {
+	Configs map[string]string
+	Default string
+}
+
+// resolve returns the language code and text search configuration for
+// lang, or for the language detected in text when lang is empty.
+func (l Languages) resolve(lang, text string) (string, string, error) {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if lang == "" {
+		lang = detectLanguage(text)
+		if _, ok := l.Configs[lang]; !ok {
+			lang = l.Default
+		}
+	}
+
+	config, ok := l.Configs[lang]
+	if !ok {
+		return "", "", dto.ErrInvalidLanguage
+	}
+	return lang, config, nil
+}
+
+// searchConfigs returns the text search configurations to match a query
+// with: the one of lang, or all of them when lang is empty.
+func (l Languages) searchConfigs(lang string) ([]string, error) {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if lang != "" {
+		config, ok := l.Configs[lang]
+		if !ok {
+			return nil, dto.ErrInvalidLanguage
+		}
+		return []string{config}, nil
+	}
+
+	var configs []string
+	for _, config := range l.Configs {
+		if !slices.Contains(configs, config) {
+			configs = append(configs, config)
+		}
+	}
+	slices.Sort(configs)
+	return configs, nil
+}
+
+// uzbekLetters are the letters of the Uzbek Cyrillic alphabet missing from
+// the Russian one.
+const uzbekLetters = "ўқғҳЎҚҒҲ"
+
+// detectLanguage guesses the language of text from its script: "uz" for
+// Uzbek letters or the oʻ and gʻ of the Uzbek Latin alphabet written with
+// a modifier letter, "ru" for other Cyrillic text, "en" for other Latin
+// text and "" when it has no letters at all.
+func detectLanguage(text string) string {
+	if strings.ContainsAny(text, uzbekLetters) {
+		return "uz"
+	}
+
+	// only the modifier letters count: with an ordinary apostrophe oʻ and
+	// gʻ are indistinguishable from English possessives and contractions
+	// such as "dog's" or "O'Brien"
+	lower := strings.ToLower(text)
+	for _, modifier := range []string{"ʻ", "ʼ"} {
+		if strings.Contains(lower, "o"+modifier) || strings.Contains(lower, "g"+modifier) {
+			return "uz"
+		}
+	}
+
+	var cyrillic, latin int
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Cyrillic, r):
+			cyrillic++
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		}
+	}
+
+	switch {
+	case cyrillic == 0 && latin == 0:
+		return ""
+	case cyrillic >= latin:
+		return "ru"
+	default:
+		return "en"
+	}
+}
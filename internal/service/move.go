@@ -0,0 +1,12 @@
+package service
+
+import (
+	"context"
+
+	"github.com/Komilov31/comment-tree/internal/dto"
+	"github.com/Komilov31/comment-tree/internal/model"
+)
+
+func (s *Service) MoveComment(ctx context.Context, id int, requester dto.Requester, move dto.MoveComment) ([]*model.Comment, error) {
+	return s.storage.MoveComment(ctx, id, move.ParentID, requester.ID)
+}
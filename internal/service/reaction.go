@@ -0,0 +1,26 @@
+package service
+
+import (
+	"context"
+
+	"github.com/Komilov31/comment-tree/internal/dto"
+	"github.com/Komilov31/comment-tree/internal/model"
+)
+
+func (s *Service) AddReaction(ctx context.Context, id int, userID string, reaction dto.Reaction) ([]model.Reaction, error) {
+	emoji, err := reaction.Normalize()
+	if err != nil {
+		return nil, err
+	}
+
+	return s.storage.AddReaction(ctx, id, userID, emoji)
+}
+
+func (s *Service) RemoveReaction(ctx context.Context, id int, userID string, reaction dto.Reaction) ([]model.Reaction, error) {
+	emoji, err := reaction.Normalize()
+	if err != nil {
+		return nil, err
+	}
+
+	return s.storage.RemoveReaction(ctx, id, userID, emoji)
+}
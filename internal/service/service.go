@@ -1,25 +1,62 @@
 package service
 
 import (
+	"context"
+	"time"
+
 	"github.com/Komilov31/comment-tree/internal/dto"
 	"github.com/Komilov31/comment-tree/internal/model"
 )
 
+// DeleteMode selects what DeleteCommentById does with a comment.
+type DeleteMode string
+
+const (
+	// DeleteModeSoft leaves a tombstone so that replies stay visible.
+	DeleteModeSoft DeleteMode = "soft"
+	// DeleteModeHard lets admins remove the comment together with all its
+	// replies; other users still leave a tombstone.
+	DeleteModeHard DeleteMode = "hard"
+)
+
 type Storage interface {
-	GetCommentsById(id int) ([]*model.Comment, error)
-	GetCommentsPaginated(config dto.CommentsPagination) ([]*model.Comment, error)
-	GetAllComments() ([]*model.Comment, error)
-	GetCommentsByTextSearch(text string) ([]*model.Comment, error)
-	CreateComment(comment dto.CreateComment) (*dto.CreateComment, error)
-	DeleteCommentById(id int) error
+	GetCommentById(ctx context.Context, id int, viewerID string) (*model.Comment, error)
+	GetCommentAncestors(ctx context.Context, id int, viewerID string) ([]model.Comment, error)
+	GetCommentContext(ctx context.Context, config dto.CommentContext) ([]*model.Comment, error)
+	GetCommentsById(ctx context.Context, config dto.CommentsPagination) ([]*model.Comment, error)
+	GetCommentsPaginated(ctx context.Context, config dto.CommentsPagination) (*model.CommentsPage, error)
+	GetCommentsByCursor(ctx context.Context, config dto.CommentsPagination) (*model.CommentsPage, error)
+	GetCommentsTree(ctx context.Context, config dto.CommentsPagination) ([]*model.Comment, error)
+	GetAllComments(ctx context.Context, config dto.CommentsPagination) (*model.CommentsPage, error)
+	GetCommentsByTextSearch(ctx context.Context, search dto.SearchText) (*model.CommentsPage, error)
+	SuggestComments(ctx context.Context, suggest dto.Suggest) (*model.Suggestions, error)
+	CreateComment(ctx context.Context, comment dto.CreateComment) (*dto.CreateComment, error)
+	UpdateComment(ctx context.Context, id int, authorID string, text string) (*model.Comment, error)
+	GetCommentRevisions(ctx context.Context, id int) ([]model.Revision, error)
+	DeleteCommentById(ctx context.Context, id int, requester dto.Requester) error
+	SoftDeleteCommentById(ctx context.Context, id int, requester dto.Requester) error
+	RestoreCommentById(ctx context.Context, id int, requester dto.Requester) error
+	MoveComment(ctx context.Context, id int, parentID *int, viewerID string) ([]*model.Comment, error)
+	PurgeTrash(ctx context.Context, trashedBefore time.Time) (int64, error)
+	Vote(ctx context.Context, id int, userID string, value int) (*model.Votes, error)
+	AddReaction(ctx context.Context, id int, userID, emoji string) ([]model.Reaction, error)
+	RemoveReaction(ctx context.Context, id int, userID, emoji string) ([]model.Reaction, error)
+	Ping(ctx context.Context) error
+	MigrationVersion(ctx context.Context) (int64, error)
 }
 
 type Service struct {
-	storage Storage
+	storage     Storage
+	deleteMode  DeleteMode
+	languages   Languages
+	suggestions *suggestCache
 }
 
-func New(storage Storage) *Service {
+func New(storage Storage, deleteMode DeleteMode, languages Languages) *Service {
 	return &Service{
-		storage: storage,
+		storage:     storage,
+		deleteMode:  deleteMode,
+		languages:   languages,
+		suggestions: newSuggestCache(suggestCacheTTL, suggestCacheSize),
 	}
 }
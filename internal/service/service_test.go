@@ -1,7 +1,9 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -16,58 +18,155 @@ type MockStorage struct {
 	mock.Mock
 }
 
-func (m *MockStorage) GetCommentsById(id int) ([]*model.Comment, error) {
-	args := m.Called(id)
+func (m *MockStorage) GetCommentsById(ctx context.Context, config dto.CommentsPagination) ([]*model.Comment, error) {
+	args := m.Called(config)
 	return args.Get(0).([]*model.Comment), args.Error(1)
 }
 
-func (m *MockStorage) GetCommentsPaginated(config dto.CommentsPagination) ([]*model.Comment, error) {
+func (m *MockStorage) GetCommentsPaginated(ctx context.Context, config dto.CommentsPagination) (*model.CommentsPage, error) {
+	args := m.Called(config)
+	return args.Get(0).(*model.CommentsPage), args.Error(1)
+}
+
+func (m *MockStorage) GetCommentsByCursor(ctx context.Context, config dto.CommentsPagination) (*model.CommentsPage, error) {
+	args := m.Called(config)
+	return args.Get(0).(*model.CommentsPage), args.Error(1)
+}
+
+func (m *MockStorage) GetCommentsTree(ctx context.Context, config dto.CommentsPagination) ([]*model.Comment, error) {
 	args := m.Called(config)
 	return args.Get(0).([]*model.Comment), args.Error(1)
 }
 
-func (m *MockStorage) GetAllComments() ([]*model.Comment, error) {
-	args := m.Called()
+func (m *MockStorage) Vote(ctx context.Context, id int, userID string, value int) (*model.Votes, error) {
+	args := m.Called(id, userID, value)
+	return args.Get(0).(*model.Votes), args.Error(1)
+}
+
+func (m *MockStorage) GetCommentById(ctx context.Context, id int, viewerID string) (*model.Comment, error) {
+	args := m.Called(id, viewerID)
+	return args.Get(0).(*model.Comment), args.Error(1)
+}
+
+func (m *MockStorage) GetCommentAncestors(ctx context.Context, id int, viewerID string) ([]model.Comment, error) {
+	args := m.Called(id, viewerID)
+	return args.Get(0).([]model.Comment), args.Error(1)
+}
+
+func (m *MockStorage) GetCommentContext(ctx context.Context, config dto.CommentContext) ([]*model.Comment, error) {
+	args := m.Called(config)
 	return args.Get(0).([]*model.Comment), args.Error(1)
 }
 
-func (m *MockStorage) GetCommentsByTextSearch(text string) ([]*model.Comment, error) {
-	args := m.Called(text)
+func (m *MockStorage) MoveComment(ctx context.Context, id int, parentID *int, viewerID string) ([]*model.Comment, error) {
+	args := m.Called(id, parentID, viewerID)
 	return args.Get(0).([]*model.Comment), args.Error(1)
 }
 
-func (m *MockStorage) CreateComment(comment dto.CreateComment) (*dto.CreateComment, error) {
+func (m *MockStorage) AddReaction(ctx context.Context, id int, userID, emoji string) ([]model.Reaction, error) {
+	args := m.Called(id, userID, emoji)
+	return args.Get(0).([]model.Reaction), args.Error(1)
+}
+
+func (m *MockStorage) RemoveReaction(ctx context.Context, id int, userID, emoji string) ([]model.Reaction, error) {
+	args := m.Called(id, userID, emoji)
+	return args.Get(0).([]model.Reaction), args.Error(1)
+}
+
+func (m *MockStorage) GetAllComments(ctx context.Context, config dto.CommentsPagination) (*model.CommentsPage, error) {
+	args := m.Called(config)
+	return args.Get(0).(*model.CommentsPage), args.Error(1)
+}
+
+func (m *MockStorage) GetCommentsByTextSearch(ctx context.Context, search dto.SearchText) (*model.CommentsPage, error) {
+	args := m.Called(search)
+	return args.Get(0).(*model.CommentsPage), args.Error(1)
+}
+
+func (m *MockStorage) SuggestComments(ctx context.Context, suggest dto.Suggest) (*model.Suggestions, error) {
+	args := m.Called(suggest)
+	return args.Get(0).(*model.Suggestions), args.Error(1)
+}
+
+func (m *MockStorage) Ping(ctx context.Context) error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+func (m *MockStorage) MigrationVersion(ctx context.Context) (int64, error) {
+	args := m.Called()
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockStorage) CreateComment(ctx context.Context, comment dto.CreateComment) (*dto.CreateComment, error) {
 	args := m.Called(comment)
 	return args.Get(0).(*dto.CreateComment), args.Error(1)
 }
 
-func (m *MockStorage) DeleteCommentById(id int) error {
+func (m *MockStorage) UpdateComment(ctx context.Context, id int, authorID string, text string) (*model.Comment, error) {
+	args := m.Called(id, authorID, text)
+	return args.Get(0).(*model.Comment), args.Error(1)
+}
+
+func (m *MockStorage) GetCommentRevisions(ctx context.Context, id int) ([]model.Revision, error) {
 	args := m.Called(id)
+	return args.Get(0).([]model.Revision), args.Error(1)
+}
+
+func (m *MockStorage) DeleteCommentById(ctx context.Context, id int, requester dto.Requester) error {
+	args := m.Called(id, requester)
 	return args.Error(0)
 }
 
+func (m *MockStorage) SoftDeleteCommentById(ctx context.Context, id int, requester dto.Requester) error {
+	args := m.Called(id, requester)
+	return args.Error(0)
+}
+
+func (m *MockStorage) RestoreCommentById(ctx context.Context, id int, requester dto.Requester) error {
+	args := m.Called(id, requester)
+	return args.Error(0)
+}
+
+func (m *MockStorage) PurgeTrash(ctx context.Context, trashedBefore time.Time) (int64, error) {
+	args := m.Called(trashedBefore)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+var testLanguages = Languages{
+	Configs: map[string]string{"ru": "russian", "en": "english", "uz": "simple"},
+	Default: "ru",
+}
+
+// allSearchConfigs are the configurations of testLanguages in order.
+var allSearchConfigs = []string{"english", "russian", "simple"}
+
 func TestNew(t *testing.T) {
 	mockStorage := &MockStorage{}
-	service := New(mockStorage)
+	service := New(mockStorage, DeleteModeSoft, testLanguages)
 	assert.NotNil(t, service)
 	assert.Equal(t, mockStorage, service.storage)
 }
 
 func TestService_CreateComment(t *testing.T) {
 	mockStorage := &MockStorage{}
-	service := New(mockStorage)
+	service := New(mockStorage, DeleteModeSoft, testLanguages)
 
 	comment := dto.CreateComment{
 		ID:        1,
 		ParentID:  nil,
+		Author:    &model.Author{ID: "u1", DisplayName: "Alice"},
 		Text:      "Test comment",
 		CreatedAt: time.Now(),
 	}
-	expected := &comment
+	stored := comment
+	stored.Language = "en"
+	stored.SearchConfig = "english"
+	expected := &stored
 
-	mockStorage.On("CreateComment", comment).Return(expected, nil)
+	mockStorage.On("CreateComment", stored).Return(expected, nil)
 
-	result, err := service.CreateComment(comment)
+	result, err := service.CreateComment(context.Background(), comment)
 
 	assert.NoError(t, err)
 	assert.Equal(t, expected, result)
@@ -76,13 +175,62 @@ func TestService_CreateComment(t *testing.T) {
 
 func TestService_DeleteCommentById(t *testing.T) {
 	mockStorage := &MockStorage{}
-	service := New(mockStorage)
+	service := New(mockStorage, DeleteModeSoft, testLanguages)
 
 	id := 1
+	requester := dto.Requester{ID: "u1"}
 
-	mockStorage.On("DeleteCommentById", id).Return(nil)
+	mockStorage.On("SoftDeleteCommentById", id, requester).Return(nil)
 
-	err := service.DeleteCommentById(id)
+	err := service.DeleteCommentById(context.Background(), id, requester)
+
+	assert.NoError(t, err)
+	mockStorage.AssertExpectations(t)
+}
+
+func TestService_DeleteCommentById_HardMode(t *testing.T) {
+	mockStorage := &MockStorage{}
+	service := New(mockStorage, DeleteModeHard, testLanguages)
+
+	id := 1
+	requester := dto.Requester{ID: "root", Admin: true}
+
+	mockStorage.On("DeleteCommentById", id, requester).Return(nil)
+
+	err := service.DeleteCommentById(context.Background(), id, requester)
+
+	assert.NoError(t, err)
+	mockStorage.AssertNotCalled(t, "SoftDeleteCommentById")
+	mockStorage.AssertExpectations(t)
+}
+
+func TestService_DeleteCommentById_HardModeAuthor(t *testing.T) {
+	mockStorage := &MockStorage{}
+	service := New(mockStorage, DeleteModeHard, testLanguages)
+
+	id := 1
+	requester := dto.Requester{ID: "u1"}
+
+	// an author must not take the replies of other users along
+	mockStorage.On("SoftDeleteCommentById", id, requester).Return(nil)
+
+	err := service.DeleteCommentById(context.Background(), id, requester)
+
+	assert.NoError(t, err)
+	mockStorage.AssertNotCalled(t, "DeleteCommentById")
+	mockStorage.AssertExpectations(t)
+}
+
+func TestService_HardDeleteCommentById(t *testing.T) {
+	mockStorage := &MockStorage{}
+	service := New(mockStorage, DeleteModeSoft, testLanguages)
+
+	id := 1
+	requester := dto.Requester{ID: "root", Admin: true}
+
+	mockStorage.On("DeleteCommentById", id, requester).Return(nil)
+
+	err := service.HardDeleteCommentById(context.Background(), id, requester)
 
 	assert.NoError(t, err)
 	mockStorage.AssertExpectations(t)
@@ -90,15 +238,17 @@ func TestService_DeleteCommentById(t *testing.T) {
 
 func TestService_GetAllComments(t *testing.T) {
 	mockStorage := &MockStorage{}
-	service := New(mockStorage)
+	service := New(mockStorage, DeleteModeSoft, testLanguages)
 
-	expected := []*model.Comment{
-		{ID: 1, Text: "Comment 1"},
+	config := dto.CommentsPagination{ThreadKey: dto.DefaultThreadKey}
+	expected := &model.CommentsPage{
+		Items: []*model.Comment{{ID: 1, Text: "Comment 1"}},
+		Page:  1,
 	}
 
-	mockStorage.On("GetAllComments").Return(expected, nil)
+	mockStorage.On("GetAllComments", config).Return(expected, nil)
 
-	result, err := service.GetAllComments()
+	result, err := service.GetAllComments(context.Background(), config)
 
 	assert.NoError(t, err)
 	assert.Equal(t, expected, result)
@@ -107,16 +257,16 @@ func TestService_GetAllComments(t *testing.T) {
 
 func TestService_GetCommentsById(t *testing.T) {
 	mockStorage := &MockStorage{}
-	service := New(mockStorage)
+	service := New(mockStorage, DeleteModeSoft, testLanguages)
 
-	id := 1
+	config := dto.CommentsPagination{ThreadKey: dto.DefaultThreadKey, ParentID: 1, Sort: dto.SortNewest}
 	expected := []*model.Comment{
 		{ID: 1, Text: "Comment 1"},
 	}
 
-	mockStorage.On("GetCommentsById", id).Return(expected, nil)
+	mockStorage.On("GetCommentsById", config).Return(expected, nil)
 
-	result, err := service.GetCommentsById(id)
+	result, err := service.GetCommentsById(context.Background(), config)
 
 	assert.NoError(t, err)
 	assert.Equal(t, expected, result)
@@ -125,56 +275,181 @@ func TestService_GetCommentsById(t *testing.T) {
 
 func TestService_GetCommentsPaginated(t *testing.T) {
 	mockStorage := &MockStorage{}
-	service := New(mockStorage)
+	service := New(mockStorage, DeleteModeSoft, testLanguages)
 
 	config := dto.CommentsPagination{
-		ParentID: 0,
-		Page:     1,
-		Limit:    10,
+		ThreadKey: dto.DefaultThreadKey,
+		ParentID:  0,
+		Page:      1,
+		Limit:     10,
 	}
-	expected := []*model.Comment{
-		{ID: 1, Text: "Comment 1"},
+	total := 1
+	expected := &model.CommentsPage{
+		Items:      []*model.Comment{{ID: 1, Text: "Comment 1"}},
+		Page:       1,
+		Limit:      10,
+		TotalCount: &total,
 	}
 
 	mockStorage.On("GetCommentsPaginated", config).Return(expected, nil)
 
-	result, err := service.GetCommentsPaginated(config)
+	result, err := service.GetCommentsPaginated(context.Background(), config)
 
 	assert.NoError(t, err)
 	assert.Equal(t, expected, result)
 	mockStorage.AssertExpectations(t)
 }
 
-func TestService_GetCommentsByTextSearch(t *testing.T) {
+func TestService_GetCommentsByCursor(t *testing.T) {
+	mockStorage := &MockStorage{}
+	service := New(mockStorage, DeleteModeSoft, testLanguages)
+
+	config := dto.CommentsPagination{ThreadKey: dto.DefaultThreadKey, Limit: 10}
+	expected := &model.CommentsPage{
+		Items: []*model.Comment{{ID: 1, Text: "Comment 1"}},
+	}
+
+	mockStorage.On("GetCommentsByCursor", config).Return(expected, nil)
+
+	result, err := service.GetCommentsByCursor(context.Background(), config)
+
+	assert.NoError(t, err)
+	assert.Equal(t, expected, result)
+	mockStorage.AssertExpectations(t)
+}
+
+func TestService_GetCommentsTree(t *testing.T) {
 	mockStorage := &MockStorage{}
-	service := New(mockStorage)
+	service := New(mockStorage, DeleteModeSoft, testLanguages)
 
-	text := "search"
+	config := dto.CommentsPagination{ThreadKey: dto.DefaultThreadKey, ParentID: 1, MaxDepth: 2, MaxChildren: 5}
 	expected := []*model.Comment{
-		{ID: 1, Text: "Comment with search"},
+		{ID: 1, Text: "Comment 1", ReplyCount: 7, More: &model.MoreReplies{Remaining: 2}},
+	}
+
+	mockStorage.On("GetCommentsTree", config).Return(expected, nil)
+
+	result, err := service.GetCommentsTree(context.Background(), config)
+
+	assert.NoError(t, err)
+	assert.Equal(t, expected, result)
+	mockStorage.AssertExpectations(t)
+}
+
+func TestService_GetCommentsByTextSearch(t *testing.T) {
+	mockStorage := &MockStorage{}
+	service := New(mockStorage, DeleteModeSoft, testLanguages)
+
+	search := dto.SearchText{ThreadKey: dto.DefaultThreadKey, Text: "search"}
+	expected := &model.CommentsPage{
+		Items: []*model.Comment{{ID: 1, Text: "Comment with search"}},
+		Page:  1,
 	}
 
-	mockStorage.On("GetCommentsByTextSearch", text).Return(expected, nil)
+	stored := search
+	stored.SearchConfigs = allSearchConfigs
+	mockStorage.On("GetCommentsByTextSearch", stored).Return(expected, nil)
 
-	result, err := service.GetCommentsByTextSearch(text)
+	result, err := service.GetCommentsByTextSearch(context.Background(), search)
 
 	assert.NoError(t, err)
 	assert.Equal(t, expected, result)
 	mockStorage.AssertExpectations(t)
 }
 
+func TestService_UpdateComment(t *testing.T) {
+	mockStorage := &MockStorage{}
+	service := New(mockStorage, DeleteModeSoft, testLanguages)
+
+	expected := &model.Comment{ID: 1, Text: "Edited comment"}
+
+	mockStorage.On("UpdateComment", 1, "u1", "Edited comment").Return(expected, nil)
+
+	result, err := service.UpdateComment(context.Background(), 1, "u1", dto.UpdateComment{Text: "Edited comment"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, expected, result)
+	mockStorage.AssertExpectations(t)
+}
+
+func TestService_GetCommentRevisions(t *testing.T) {
+	mockStorage := &MockStorage{}
+	service := New(mockStorage, DeleteModeSoft, testLanguages)
+
+	revisions := []model.Revision{
+		{Version: 1, Text: "hello world"},
+		{Version: 2, Text: "hello brave new world"},
+		{Version: 3, Text: "goodbye brave new world"},
+	}
+
+	mockStorage.On("GetCommentRevisions", 1).Return(revisions, nil)
+
+	result, err := service.GetCommentRevisions(context.Background(), 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []model.DiffOp{{Op: "insert", Text: "hello world"}}, result[0].Diff)
+	assert.Equal(t, []model.DiffOp{
+		{Op: "equal", Text: "hello"},
+		{Op: "insert", Text: "brave new"},
+		{Op: "equal", Text: "world"},
+	}, result[1].Diff)
+	assert.Equal(t, []model.DiffOp{
+		{Op: "delete", Text: "hello"},
+		{Op: "insert", Text: "goodbye"},
+		{Op: "equal", Text: "brave new world"},
+	}, result[2].Diff)
+	mockStorage.AssertExpectations(t)
+}
+
+func TestService_RestoreCommentById(t *testing.T) {
+	mockStorage := &MockStorage{}
+	service := New(mockStorage, DeleteModeSoft, testLanguages)
+
+	requester := dto.Requester{ID: "u1"}
+
+	mockStorage.On("RestoreCommentById", 1, requester).Return(nil)
+
+	err := service.RestoreCommentById(context.Background(), 1, requester)
+
+	assert.NoError(t, err)
+	mockStorage.AssertExpectations(t)
+}
+
+func TestService_RunTrashPurger(t *testing.T) {
+	mockStorage := &MockStorage{}
+	service := New(mockStorage, DeleteModeSoft, testLanguages)
+
+	retention := 24 * time.Hour
+	before := time.Now().Add(-retention)
+
+	mockStorage.On("PurgeTrash", mock.MatchedBy(func(trashedBefore time.Time) bool {
+		return !trashedBefore.Before(before) && trashedBefore.Before(time.Now().Add(-retention+time.Minute))
+	})).Return(int64(3), nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	service.RunTrashPurger(ctx, time.Hour, retention)
+
+	mockStorage.AssertNumberOfCalls(t, "PurgeTrash", 1)
+	mockStorage.AssertExpectations(t)
+}
+
 // Test error cases
 func TestService_CreateComment_Error(t *testing.T) {
 	mockStorage := &MockStorage{}
-	service := New(mockStorage)
+	service := New(mockStorage, DeleteModeSoft, testLanguages)
 
 	comment := dto.CreateComment{
 		Text: "Test comment",
 	}
+	stored := comment
+	stored.Language = "en"
+	stored.SearchConfig = "english"
 
-	mockStorage.On("CreateComment", comment).Return((*dto.CreateComment)(nil), errors.New("storage error"))
+	mockStorage.On("CreateComment", stored).Return((*dto.CreateComment)(nil), errors.New("storage error"))
 
-	result, err := service.CreateComment(comment)
+	result, err := service.CreateComment(context.Background(), comment)
 
 	assert.Error(t, err)
 	assert.Nil(t, result)
@@ -183,13 +458,14 @@ func TestService_CreateComment_Error(t *testing.T) {
 
 func TestService_DeleteCommentById_Error(t *testing.T) {
 	mockStorage := &MockStorage{}
-	service := New(mockStorage)
+	service := New(mockStorage, DeleteModeSoft, testLanguages)
 
 	id := 1
+	requester := dto.Requester{ID: "u1"}
 
-	mockStorage.On("DeleteCommentById", id).Return(errors.New("storage error"))
+	mockStorage.On("SoftDeleteCommentById", id, requester).Return(errors.New("storage error"))
 
-	err := service.DeleteCommentById(id)
+	err := service.DeleteCommentById(context.Background(), id, requester)
 
 	assert.Error(t, err)
 	mockStorage.AssertExpectations(t)
@@ -197,11 +473,13 @@ func TestService_DeleteCommentById_Error(t *testing.T) {
 
 func TestService_GetAllComments_Error(t *testing.T) {
 	mockStorage := &MockStorage{}
-	service := New(mockStorage)
+	service := New(mockStorage, DeleteModeSoft, testLanguages)
 
-	mockStorage.On("GetAllComments").Return(([]*model.Comment)(nil), errors.New("storage error"))
+	config := dto.CommentsPagination{ThreadKey: dto.DefaultThreadKey}
 
-	result, err := service.GetAllComments()
+	mockStorage.On("GetAllComments", config).Return((*model.CommentsPage)(nil), errors.New("storage error"))
+
+	result, err := service.GetAllComments(context.Background(), config)
 
 	assert.Error(t, err)
 	assert.Nil(t, result)
@@ -210,13 +488,13 @@ func TestService_GetAllComments_Error(t *testing.T) {
 
 func TestService_GetCommentsById_Error(t *testing.T) {
 	mockStorage := &MockStorage{}
-	service := New(mockStorage)
+	service := New(mockStorage, DeleteModeSoft, testLanguages)
 
-	id := 1
+	config := dto.CommentsPagination{ThreadKey: dto.DefaultThreadKey, ParentID: 1}
 
-	mockStorage.On("GetCommentsById", id).Return(([]*model.Comment)(nil), errors.New("storage error"))
+	mockStorage.On("GetCommentsById", config).Return(([]*model.Comment)(nil), errors.New("storage error"))
 
-	result, err := service.GetCommentsById(id)
+	result, err := service.GetCommentsById(context.Background(), config)
 
 	assert.Error(t, err)
 	assert.Nil(t, result)
@@ -225,13 +503,13 @@ func TestService_GetCommentsById_Error(t *testing.T) {
 
 func TestService_GetCommentsPaginated_Error(t *testing.T) {
 	mockStorage := &MockStorage{}
-	service := New(mockStorage)
+	service := New(mockStorage, DeleteModeSoft, testLanguages)
 
 	config := dto.CommentsPagination{}
 
-	mockStorage.On("GetCommentsPaginated", config).Return(([]*model.Comment)(nil), errors.New("storage error"))
+	mockStorage.On("GetCommentsPaginated", config).Return((*model.CommentsPage)(nil), errors.New("storage error"))
 
-	result, err := service.GetCommentsPaginated(config)
+	result, err := service.GetCommentsPaginated(context.Background(), config)
 
 	assert.Error(t, err)
 	assert.Nil(t, result)
@@ -240,15 +518,514 @@ func TestService_GetCommentsPaginated_Error(t *testing.T) {
 
 func TestService_GetCommentsByTextSearch_Error(t *testing.T) {
 	mockStorage := &MockStorage{}
-	service := New(mockStorage)
+	service := New(mockStorage, DeleteModeSoft, testLanguages)
 
-	text := "search"
+	search := dto.SearchText{ThreadKey: dto.DefaultThreadKey, Text: "search"}
 
-	mockStorage.On("GetCommentsByTextSearch", text).Return(([]*model.Comment)(nil), errors.New("storage error"))
+	stored := search
+	stored.SearchConfigs = allSearchConfigs
+	mockStorage.On("GetCommentsByTextSearch", stored).Return((*model.CommentsPage)(nil), errors.New("storage error"))
 
-	result, err := service.GetCommentsByTextSearch(text)
+	result, err := service.GetCommentsByTextSearch(context.Background(), search)
 
 	assert.Error(t, err)
 	assert.Nil(t, result)
 	mockStorage.AssertExpectations(t)
 }
+
+func TestService_UpdateComment_Error(t *testing.T) {
+	mockStorage := &MockStorage{}
+	service := New(mockStorage, DeleteModeSoft, testLanguages)
+
+	mockStorage.On("UpdateComment", 1, "u1", "Edited comment").Return((*model.Comment)(nil), errors.New("storage error"))
+
+	result, err := service.UpdateComment(context.Background(), 1, "u1", dto.UpdateComment{Text: "Edited comment"})
+
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	mockStorage.AssertExpectations(t)
+}
+
+func TestService_GetCommentRevisions_Error(t *testing.T) {
+	mockStorage := &MockStorage{}
+	service := New(mockStorage, DeleteModeSoft, testLanguages)
+
+	mockStorage.On("GetCommentRevisions", 1).Return(([]model.Revision)(nil), errors.New("storage error"))
+
+	result, err := service.GetCommentRevisions(context.Background(), 1)
+
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	mockStorage.AssertExpectations(t)
+}
+
+func TestService_RunTrashPurger_Error(t *testing.T) {
+	mockStorage := &MockStorage{}
+	service := New(mockStorage, DeleteModeSoft, testLanguages)
+
+	mockStorage.On("PurgeTrash", mock.AnythingOfType("time.Time")).Return(int64(0), errors.New("storage error"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	service.RunTrashPurger(ctx, time.Hour, time.Hour)
+
+	mockStorage.AssertExpectations(t)
+}
+
+func TestService_Vote(t *testing.T) {
+	mockStorage := &MockStorage{}
+	service := New(mockStorage, DeleteModeSoft, testLanguages)
+
+	expected := &model.Votes{Score: -1, Downvotes: 1, MyVote: -1}
+
+	mockStorage.On("Vote", 1, "u1", -1).Return(expected, nil)
+
+	result, err := service.Vote(context.Background(), 1, "u1", dto.Vote{Vote: dto.VoteDown})
+
+	assert.NoError(t, err)
+	assert.Equal(t, expected, result)
+	mockStorage.AssertExpectations(t)
+}
+
+func TestService_Vote_Clear(t *testing.T) {
+	mockStorage := &MockStorage{}
+	service := New(mockStorage, DeleteModeSoft, testLanguages)
+
+	expected := &model.Votes{}
+
+	mockStorage.On("Vote", 1, "u1", 0).Return(expected, nil)
+
+	result, err := service.Vote(context.Background(), 1, "u1", dto.Vote{Vote: dto.VoteClear})
+
+	assert.NoError(t, err)
+	assert.Equal(t, expected, result)
+	mockStorage.AssertExpectations(t)
+}
+
+func TestService_Vote_Invalid(t *testing.T) {
+	mockStorage := &MockStorage{}
+	service := New(mockStorage, DeleteModeSoft, testLanguages)
+
+	result, err := service.Vote(context.Background(), 1, "u1", dto.Vote{Vote: "sideways"})
+
+	assert.ErrorIs(t, err, dto.ErrInvalidVote)
+	assert.Nil(t, result)
+	mockStorage.AssertNotCalled(t, "Vote")
+}
+
+func TestService_AddReaction(t *testing.T) {
+	mockStorage := &MockStorage{}
+	service := New(mockStorage, DeleteModeSoft, testLanguages)
+
+	expected := []model.Reaction{{Emoji: "🔥", Count: 1, Reacted: true}}
+
+	mockStorage.On("AddReaction", 1, "u1", "🔥").Return(expected, nil)
+
+	result, err := service.AddReaction(context.Background(), 1, "u1", dto.Reaction{Emoji: " 🔥 "})
+
+	assert.NoError(t, err)
+	assert.Equal(t, expected, result)
+	mockStorage.AssertExpectations(t)
+}
+
+func TestService_AddReaction_Invalid(t *testing.T) {
+	for _, emoji := range []string{"", "   ", "thumbs up", "this-reaction-name-is-far-too-long"} {
+		mockStorage := &MockStorage{}
+		service := New(mockStorage, DeleteModeSoft, testLanguages)
+
+		result, err := service.AddReaction(context.Background(), 1, "u1", dto.Reaction{Emoji: emoji})
+
+		assert.ErrorIs(t, err, dto.ErrInvalidReaction, emoji)
+		assert.Nil(t, result)
+		mockStorage.AssertNotCalled(t, "AddReaction")
+	}
+}
+
+func TestService_RemoveReaction(t *testing.T) {
+	mockStorage := &MockStorage{}
+	service := New(mockStorage, DeleteModeSoft, testLanguages)
+
+	mockStorage.On("RemoveReaction", 1, "u1", "🔥").Return([]model.Reaction{}, nil)
+
+	result, err := service.RemoveReaction(context.Background(), 1, "u1", dto.Reaction{Emoji: "🔥"})
+
+	assert.NoError(t, err)
+	assert.Empty(t, result)
+	mockStorage.AssertExpectations(t)
+}
+
+func TestService_GetCommentById(t *testing.T) {
+	mockStorage := &MockStorage{}
+	service := New(mockStorage, DeleteModeSoft, testLanguages)
+
+	expected := &model.Comment{ID: 1, Text: "Comment"}
+
+	mockStorage.On("GetCommentById", 1, "u1").Return(expected, nil)
+
+	result, err := service.GetCommentById(context.Background(), 1, "u1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, expected, result)
+	mockStorage.AssertExpectations(t)
+}
+
+func TestService_GetCommentAncestors(t *testing.T) {
+	mockStorage := &MockStorage{}
+	service := New(mockStorage, DeleteModeSoft, testLanguages)
+
+	expected := []model.Comment{{ID: 1, Text: "Root"}}
+
+	mockStorage.On("GetCommentAncestors", 2, "").Return(expected, nil)
+
+	result, err := service.GetCommentAncestors(context.Background(), 2, "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, expected, result)
+	mockStorage.AssertExpectations(t)
+}
+
+func TestService_GetCommentContext(t *testing.T) {
+	mockStorage := &MockStorage{}
+	service := New(mockStorage, DeleteModeSoft, testLanguages)
+
+	config := dto.CommentContext{ID: 2, Up: 1, Down: 3}
+	expected := []*model.Comment{{ID: 1, Children: []*model.Comment{{ID: 2}}}}
+
+	mockStorage.On("GetCommentContext", config).Return(expected, nil)
+
+	result, err := service.GetCommentContext(context.Background(), config)
+
+	assert.NoError(t, err)
+	assert.Equal(t, expected, result)
+	mockStorage.AssertExpectations(t)
+}
+
+func TestService_MoveComment(t *testing.T) {
+	mockStorage := &MockStorage{}
+	service := New(mockStorage, DeleteModeSoft, testLanguages)
+
+	parentID := 7
+	expected := []*model.Comment{{ID: 3, ParentID: &parentID}}
+
+	mockStorage.On("MoveComment", 3, &parentID, "mod").Return(expected, nil)
+
+	result, err := service.MoveComment(context.Background(), 3, dto.Requester{ID: "mod", Admin: true}, dto.MoveComment{ParentID: &parentID})
+
+	assert.NoError(t, err)
+	assert.Equal(t, expected, result)
+	mockStorage.AssertExpectations(t)
+}
+
+func TestService_GetCommentsByTextSearch_Highlight(t *testing.T) {
+	mockStorage := &MockStorage{}
+	service := New(mockStorage, DeleteModeSoft, testLanguages)
+
+	search := dto.SearchText{
+		ThreadKey: dto.DefaultThreadKey,
+		Text:      "search",
+		Highlight: &dto.Highlight{StartSel: "<mark>", MaxFragments: 2},
+	}
+	expected := &model.CommentsPage{Items: []*model.Comment{{ID: 1}}}
+
+	withOptions := search
+	withOptions.HighlightOptions = `StartSel="<mark>", StopSel="</b>", MaxFragments=2`
+	withOptions.SearchConfigs = allSearchConfigs
+	mockStorage.On("GetCommentsByTextSearch", withOptions).Return(expected, nil)
+
+	result, err := service.GetCommentsByTextSearch(context.Background(), search)
+
+	assert.NoError(t, err)
+	assert.Equal(t, expected, result)
+	mockStorage.AssertExpectations(t)
+}
+
+func TestService_GetCommentsByTextSearch_InvalidHighlight(t *testing.T) {
+	highlights := []dto.Highlight{
+		{StartSel: `<span class="hit">`},
+		{StopSel: "</b>,"},
+		{StartSel: strings.Repeat("x", 33)},
+		{MaxFragments: -1},
+		{MaxFragments: 11},
+	}
+
+	for _, highlight := range highlights {
+		mockStorage := &MockStorage{}
+		service := New(mockStorage, DeleteModeSoft, testLanguages)
+
+		result, err := service.GetCommentsByTextSearch(context.Background(), dto.SearchText{Text: "search", Highlight: &highlight})
+
+		assert.ErrorIs(t, err, dto.ErrInvalidHighlight)
+		assert.Nil(t, result)
+		mockStorage.AssertNotCalled(t, "GetCommentsByTextSearch")
+	}
+}
+
+func TestService_CreateComment_Language(t *testing.T) {
+	tests := []struct {
+		language     string
+		text         string
+		wantLanguage string
+		wantConfig   string
+	}{
+		{"", "Привет, как дела?", "ru", "russian"},
+		{"", "Hello there", "en", "english"},
+		{"", "Oʻzbekiston haqida", "uz", "simple"},
+		{"", "Ўзбекистон ҳақида", "uz", "simple"},
+		{"", "Gʼalaba va oʼzbek tili", "uz", "simple"},
+		// ordinary apostrophes after o and g are English, not Uzbek
+		{"", "The dog's bone", "en", "english"},
+		{"", "Something's wrong", "en", "english"},
+		{"", "Who's there?", "en", "english"},
+		{"", "O'Brien wrote it", "en", "english"},
+		{"", "Chicago’s best dog’s toy", "en", "english"},
+		{"", "‘Go‘ means leave", "en", "english"},
+		{"", "123 !!!", "ru", "russian"},
+		{"EN", "Привет", "en", "english"},
+	}
+
+	for _, tt := range tests {
+		mockStorage := &MockStorage{}
+		service := New(mockStorage, DeleteModeSoft, testLanguages)
+
+		comment := dto.CreateComment{Text: tt.text, Language: tt.language}
+		stored := comment
+		stored.Language = tt.wantLanguage
+		stored.SearchConfig = tt.wantConfig
+
+		mockStorage.On("CreateComment", stored).Return(&stored, nil)
+
+		result, err := service.CreateComment(context.Background(), comment)
+
+		assert.NoError(t, err, tt.text)
+		assert.Equal(t, tt.wantLanguage, result.Language, tt.text)
+		mockStorage.AssertExpectations(t)
+	}
+}
+
+func TestService_CreateComment_UnsupportedLanguage(t *testing.T) {
+	mockStorage := &MockStorage{}
+	service := New(mockStorage, DeleteModeSoft, testLanguages)
+
+	result, err := service.CreateComment(context.Background(), dto.CreateComment{Text: "Bonjour", Language: "fr"})
+
+	assert.ErrorIs(t, err, dto.ErrInvalidLanguage)
+	assert.Nil(t, result)
+	mockStorage.AssertNotCalled(t, "CreateComment")
+}
+
+func TestService_GetCommentsByTextSearch_Language(t *testing.T) {
+	mockStorage := &MockStorage{}
+	service := New(mockStorage, DeleteModeSoft, testLanguages)
+
+	search := dto.SearchText{ThreadKey: dto.DefaultThreadKey, Text: "search", Lang: "en"}
+	expected := &model.CommentsPage{Items: []*model.Comment{{ID: 1}}}
+
+	stored := search
+	stored.SearchConfigs = []string{"english"}
+	mockStorage.On("GetCommentsByTextSearch", stored).Return(expected, nil)
+
+	result, err := service.GetCommentsByTextSearch(context.Background(), search)
+
+	assert.NoError(t, err)
+	assert.Equal(t, expected, result)
+	mockStorage.AssertExpectations(t)
+}
+
+func TestService_GetCommentsByTextSearch_UnsupportedLanguage(t *testing.T) {
+	mockStorage := &MockStorage{}
+	service := New(mockStorage, DeleteModeSoft, testLanguages)
+
+	result, err := service.GetCommentsByTextSearch(context.Background(), dto.SearchText{Text: "search", Lang: "fr"})
+
+	assert.ErrorIs(t, err, dto.ErrInvalidLanguage)
+	assert.Nil(t, result)
+	mockStorage.AssertNotCalled(t, "GetCommentsByTextSearch")
+}
+
+func TestService_GetCommentsByTextSearch_Filters(t *testing.T) {
+	mockStorage := &MockStorage{}
+	service := New(mockStorage, DeleteModeSoft, testLanguages)
+
+	from := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	minScore := 3
+	search := dto.SearchText{
+		ThreadKey: dto.DefaultThreadKey,
+		Text:      `"дерево комментариев" -поиск`,
+		From:      &from,
+		To:        &to,
+		AuthorID:  "alice",
+		MinScore:  &minScore,
+		RootsOnly: true,
+	}
+	expected := &model.CommentsPage{Items: []*model.Comment{{ID: 1}}}
+
+	stored := search
+	stored.SearchConfigs = allSearchConfigs
+	mockStorage.On("GetCommentsByTextSearch", stored).Return(expected, nil)
+
+	result, err := service.GetCommentsByTextSearch(context.Background(), search)
+
+	assert.NoError(t, err)
+	assert.Equal(t, expected, result)
+	mockStorage.AssertExpectations(t)
+}
+
+func TestService_GetCommentsByTextSearch_InvalidDateRange(t *testing.T) {
+	mockStorage := &MockStorage{}
+	service := New(mockStorage, DeleteModeSoft, testLanguages)
+
+	from := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, -1)
+
+	result, err := service.GetCommentsByTextSearch(context.Background(), dto.SearchText{Text: "search", From: &from, To: &to})
+
+	assert.ErrorIs(t, err, dto.ErrInvalidSearchFilter)
+	assert.Nil(t, result)
+	mockStorage.AssertNotCalled(t, "GetCommentsByTextSearch")
+}
+
+func TestService_GetCommentsByTextSearch_InvalidMode(t *testing.T) {
+	tooHigh, negative := 1.5, -0.1
+	searches := []dto.SearchText{
+		{Text: "search", Mode: "exact"},
+		{Text: "search", Mode: dto.SearchFuzzy, Similarity: &tooHigh},
+		{Text: "search", Mode: dto.SearchBoth, Similarity: &negative},
+	}
+
+	for _, search := range searches {
+		mockStorage := &MockStorage{}
+		service := New(mockStorage, DeleteModeSoft, testLanguages)
+
+		result, err := service.GetCommentsByTextSearch(context.Background(), search)
+
+		assert.ErrorIs(t, err, dto.ErrInvalidSearchFilter)
+		assert.Nil(t, result)
+		mockStorage.AssertNotCalled(t, "GetCommentsByTextSearch")
+	}
+}
+
+func TestService_SuggestComments(t *testing.T) {
+	mockStorage := &MockStorage{}
+	service := New(mockStorage, DeleteModeSoft, testLanguages)
+
+	suggest := dto.Suggest{ThreadKey: dto.DefaultThreadKey, Query: "Дерево, КОММ", Limit: 5}
+	expected := &model.Suggestions{
+		Comments: []model.Suggestion{{ID: 1, Excerpt: "<b>дерево</b> <b>комментариев</b>"}},
+		Terms:    []string{"комментар"},
+	}
+
+	stored := suggest
+	stored.TSQuery = "дерево:* & комм:*"
+	stored.Prefix = "комм"
+	stored.SearchConfigs = allSearchConfigs
+	mockStorage.On("SuggestComments", stored).Return(expected, nil).Once()
+
+	for range 2 {
+		result, err := service.SuggestComments(context.Background(), suggest)
+
+		assert.NoError(t, err)
+		assert.Equal(t, expected, result)
+	}
+	mockStorage.AssertExpectations(t)
+}
+
+func TestService_SuggestComments_StemmedPrefix(t *testing.T) {
+	mockStorage := &MockStorage{}
+	service := New(mockStorage, DeleteModeSoft, testLanguages)
+
+	// "running" is indexed as "run" by the english configuration, so the
+	// prefix is passed on as typed and normalised by the storage
+	suggest := dto.Suggest{ThreadKey: dto.DefaultThreadKey, Query: "Running", Limit: 5, Lang: "en"}
+	expected := &model.Suggestions{
+		Comments: []model.Suggestion{{ID: 1, Excerpt: "<b>run</b> the tests"}},
+		Terms:    []string{"run", "runner"},
+	}
+
+	stored := suggest
+	stored.TSQuery = "running:*"
+	stored.Prefix = "running"
+	stored.SearchConfigs = []string{"english"}
+	mockStorage.On("SuggestComments", stored).Return(expected, nil)
+
+	result, err := service.SuggestComments(context.Background(), suggest)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"run", "runner"}, result.Terms)
+	mockStorage.AssertExpectations(t)
+}
+
+func TestService_SuggestComments_CacheExpires(t *testing.T) {
+	mockStorage := &MockStorage{}
+	service := New(mockStorage, DeleteModeSoft, testLanguages)
+
+	now := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+	service.suggestions.now = func() time.Time { return now }
+
+	suggest := dto.Suggest{ThreadKey: dto.DefaultThreadKey, Query: "дер", Limit: 5}
+	expected := &model.Suggestions{Comments: []model.Suggestion{{ID: 1}}, Terms: []string{}}
+
+	mockStorage.On("SuggestComments", mock.Anything).Return(expected, nil).Twice()
+
+	_, err := service.SuggestComments(context.Background(), suggest)
+	assert.NoError(t, err)
+
+	now = now.Add(suggestCacheTTL)
+	_, err = service.SuggestComments(context.Background(), suggest)
+	assert.NoError(t, err)
+
+	mockStorage.AssertExpectations(t)
+}
+
+func TestService_SuggestComments_EmptyQuery(t *testing.T) {
+	mockStorage := &MockStorage{}
+	service := New(mockStorage, DeleteModeSoft, testLanguages)
+
+	result, err := service.SuggestComments(context.Background(), dto.Suggest{Query: " -!?", Limit: 5})
+
+	assert.NoError(t, err)
+	assert.Empty(t, result.Comments)
+	assert.Empty(t, result.Terms)
+	mockStorage.AssertNotCalled(t, "SuggestComments")
+}
+
+func TestService_CheckReadiness(t *testing.T) {
+	mockStorage := &MockStorage{}
+	service := New(mockStorage, DeleteModeSoft, testLanguages)
+
+	mockStorage.On("Ping").Return(nil)
+	mockStorage.On("MigrationVersion").Return(int64(20251013090000), nil)
+
+	version, err := service.CheckReadiness(context.Background(), 20251013090000)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(20251013090000), version)
+	mockStorage.AssertExpectations(t)
+}
+
+func TestService_CheckReadiness_MigrationsBehind(t *testing.T) {
+	mockStorage := &MockStorage{}
+	service := New(mockStorage, DeleteModeSoft, testLanguages)
+
+	mockStorage.On("Ping").Return(nil)
+	mockStorage.On("MigrationVersion").Return(int64(20251012090000), nil)
+
+	version, err := service.CheckReadiness(context.Background(), 20251013090000)
+
+	assert.ErrorIs(t, err, ErrMigrationsBehind)
+	assert.Equal(t, int64(20251012090000), version)
+	mockStorage.AssertExpectations(t)
+}
+
+func TestService_CheckReadiness_DatabaseUnreachable(t *testing.T) {
+	mockStorage := &MockStorage{}
+	service := New(mockStorage, DeleteModeSoft, testLanguages)
+
+	mockStorage.On("Ping").Return(errors.New("could not reach db"))
+
+	_, err := service.CheckReadiness(context.Background(), 20251013090000)
+
+	assert.Error(t, err)
+	mockStorage.AssertNotCalled(t, "MigrationVersion")
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/Komilov31/comment-tree/internal/dto"
+	"github.com/Komilov31/comment-tree/internal/model"
+)
+
+const (
+	// suggestCacheTTL is short enough for new comments to show up quickly
+	// and long enough to answer the repeated requests of a user typing,
+	// deleting and retyping a word.
+	suggestCacheTTL = 30 * time.Second
+	// suggestCacheSize bounds the memory taken by cached suggestions.
+	suggestCacheSize = 1024
+	// maxSuggestWords bounds the size of the tsquery built from a query.
+	maxSuggestWords = 8
+)
+
+// SuggestComments returns autocomplete suggestions for the partly typed
+// query of suggest. Every word of the query is matched as a prefix and
+// results are cached for suggestCacheTTL, so callers must not modify them.
+func (s *Service) SuggestComments(ctx context.Context, suggest dto.Suggest) (*model.Suggestions, error) {
+	searchConfigs, err := s.languages.searchConfigs(suggest.Lang)
+	if err != nil {
+		return nil, err
+	}
+
+	words := suggestWords(suggest.Query)
+	if len(words) == 0 {
+		return &model.Suggestions{Comments: []model.Suggestion{}, Terms: []string{}}, nil
+	}
+
+	suggest.TSQuery = prefixQuery(words)
+	suggest.Prefix = words[len(words)-1]
+	suggest.SearchConfigs = searchConfigs
+
+	key := strings.Join([]string{
+		suggest.ThreadKey,
+		strings.Join(searchConfigs, ","),
+		suggest.TSQuery,
+		strconv.Itoa(suggest.Limit),
+	}, "\x00")
+	if suggestions, ok := s.suggestions.get(key); ok {
+		return suggestions, nil
+	}
+
+	suggestions, err := s.storage.SuggestComments(ctx, suggest)
+	if err != nil {
+		return nil, err
+	}
+
+	s.suggestions.put(key, suggestions)
+	return suggestions, nil
+}
+
+// suggestWords splits query into lower case words of letters and digits,
+// which leaves nothing to escape in a tsquery.
+func suggestWords(query string) []string {
+	words := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) > maxSuggestWords {
+		words = words[len(words)-maxSuggestWords:]
+	}
+	return words
+}
+
+// prefixQuery builds a tsquery matching every one of words as a prefix.
+func prefixQuery(words []string) string {
+	terms := make([]string, len(words))
+	for i, word := range words {
+		terms[i] = word + ":*"
+	}
+	return strings.Join(terms, " & ")
+}
+
+// suggestCache keeps suggestions for a fixed time. When it is full, expired
+// entries are dropped and, if that is not enough, all of them.
+type suggestCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	size    int
+	now     func() time.Time
+	entries map[string]suggestEntry
+}
+
+type suggestEntry struct {
+	suggestions *model.Suggestions
+	expiresAt   time.Time
+}
+
+func newSuggestCache(ttl time.Duration, size int) *suggestCache {
+	return &suggestCache{
+		ttl:     ttl,
+		size:    size,
+		now:     time.Now,
+		entries: make(map[string]suggestEntry),
+	}
+}
+
+func (c *suggestCache) get(key string) (*model.Suggestions, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok || !c.now().Before(entry.expiresAt) {
+		return nil, false
+	}
+	return entry.suggestions, true
+}
+
+func (c *suggestCache) put(key string, suggestions *model.Suggestions) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if len(c.entries) >= c.size {
+		for key, entry := range c.entries {
+			if !now.Before(entry.expiresAt) {
+				delete(c.entries, key)
+			}
+		}
+		if len(c.entries) >= c.size {
+			clear(c.entries)
+		}
+	}
+
+	c.entries[key] = suggestEntry{suggestions: suggestions, expiresAt: now.Add(c.ttl)}
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/Komilov31/comment-tree/internal/dto"
+	"github.com/wb-go/wbf/zlog"
+)
+
+func (s *Service) RestoreCommentById(ctx context.Context, id int, requester dto.Requester) error {
+	return s.storage.RestoreCommentById(ctx, id, requester)
+}
+
+// RunTrashPurger permanently removes subtrees that have been in the trash
+// longer than retention. It purges once right away and then every interval
+// until ctx is cancelled.
+func (s *Service) RunTrashPurger(ctx context.Context, interval, retention time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		s.purgeTrash(ctx, retention)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Service) purgeTrash(ctx context.Context, retention time.Duration) {
+	purged, err := s.storage.PurgeTrash(ctx, time.Now().Add(-retention))
+	if err != nil {
+		zlog.Logger.Error().Msgf("could not purge trash: %s", err.Error())
+		return
+	}
+
+	if purged > 0 {
+		zlog.Logger.Info().Msgf("purged %d comments from trash", purged)
+	}
+}
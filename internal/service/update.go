@@ -0,0 +1,27 @@
+package service
+
+import (
+	"context"
+
+	"github.com/Komilov31/comment-tree/internal/dto"
+	"github.com/Komilov31/comment-tree/internal/model"
+)
+
+func (s *Service) UpdateComment(ctx context.Context, id int, authorID string, update dto.UpdateComment) (*model.Comment, error) {
+	return s.storage.UpdateComment(ctx, id, authorID, update.Text)
+}
+
+func (s *Service) GetCommentRevisions(ctx context.Context, id int) ([]model.Revision, error) {
+	revisions, err := s.storage.GetCommentRevisions(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := ""
+	for i := range revisions {
+		revisions[i].Diff = diffWords(previous, revisions[i].Text)
+		previous = revisions[i].Text
+	}
+
+	return revisions, nil
+}
@@ -0,0 +1,17 @@
+package service
+
+import (
+	"context"
+
+	"github.com/Komilov31/comment-tree/internal/dto"
+	"github.com/Komilov31/comment-tree/internal/model"
+)
+
+func (s *Service) Vote(ctx context.Context, id int, userID string, vote dto.Vote) (*model.Votes, error) {
+	value, err := vote.Value()
+	if err != nil {
+		return nil, err
+	}
+
+	return s.storage.Vote(ctx, id, userID, value)
+}
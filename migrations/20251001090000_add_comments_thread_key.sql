@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE comments ADD COLUMN thread_key TEXT NOT NULL DEFAULT 'default';
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX idx_comments_thread_key ON comments(thread_key, created_at);
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_comments_thread_key;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE comments DROP COLUMN IF EXISTS thread_key;
+-- +goose StatementEnd
@@ -0,0 +1,46 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE comments ADD COLUMN edited_at TIMESTAMP;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS comment_revisions(
+    id SERIAL PRIMARY KEY,
+    comment_id INT NOT NULL REFERENCES comments(id) ON DELETE CASCADE,
+    text TEXT,
+    created_at TIMESTAMP NOT NULL,
+    replaced_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX idx_comment_revisions_comment_id ON comment_revisions(comment_id, id);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS tsvectorupdate ON comments;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER tsvectorupdate BEFORE INSERT OR UPDATE OF text
+ON comments FOR EACH ROW EXECUTE FUNCTION comments_search_vector_update();
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS tsvectorupdate ON comments;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER tsvectorupdate BEFORE INSERT
+ON comments FOR EACH ROW EXECUTE FUNCTION comments_search_vector_update();
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE IF EXISTS comment_revisions;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE comments DROP COLUMN IF EXISTS edited_at;
+-- +goose StatementEnd
@@ -0,0 +1,43 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS comments_trash(
+    id INT PRIMARY KEY,
+    parent_id INT,
+    thread_key TEXT NOT NULL,
+    author_id TEXT,
+    text TEXT,
+    created_at TIMESTAMP,
+    edited_at TIMESTAMP,
+    deleted_at TIMESTAMP,
+    trash_root_id INT NOT NULL,
+    trashed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX idx_comments_trash_root_id ON comments_trash(trash_root_id);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX idx_comments_trash_trashed_at ON comments_trash(trashed_at);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS comment_revisions_trash(
+    id INT PRIMARY KEY,
+    comment_id INT NOT NULL REFERENCES comments_trash(id) ON DELETE CASCADE,
+    text TEXT,
+    created_at TIMESTAMP NOT NULL,
+    replaced_at TIMESTAMP
+);
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS comment_revisions_trash;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE IF EXISTS comments_trash;
+-- +goose StatementEnd
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_comments_thread_key;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX idx_comments_thread_key_created_at_id ON comments(thread_key, created_at, id);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX idx_comments_parent_id_created_at_id ON comments(parent_id, created_at, id);
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_comments_parent_id_created_at_id;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_comments_thread_key_created_at_id;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX idx_comments_thread_key ON comments(thread_key, created_at);
+-- +goose StatementEnd
@@ -0,0 +1,108 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE comments
+    ADD COLUMN reply_count INT NOT NULL DEFAULT 0,
+    ADD COLUMN descendant_count INT NOT NULL DEFAULT 0,
+    ADD COLUMN last_reply_at TIMESTAMP;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+UPDATE comments c SET reply_count = (SELECT COUNT(*) FROM comments ch WHERE ch.parent_id = c.id);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+WITH RECURSIVE subtree AS (
+    SELECT id AS ancestor_id, id, created_at FROM comments
+
+    UNION ALL
+
+    SELECT s.ancestor_id, c.id, c.created_at
+    FROM comments c
+    INNER JOIN subtree s ON c.parent_id = s.id
+)
+UPDATE comments c
+SET descendant_count = stats.descendant_count, last_reply_at = stats.last_reply_at
+FROM (
+    SELECT ancestor_id, COUNT(*) - 1 AS descendant_count, MAX(created_at) FILTER (WHERE id <> ancestor_id) AS last_reply_at
+    FROM subtree
+    GROUP BY ancestor_id
+) stats
+WHERE stats.ancestor_id = c.id;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE FUNCTION comments_reply_stats_insert() RETURNS trigger AS $func$
+DECLARE
+  ancestor_id INT := NEW.parent_id;
+BEGIN
+  WHILE ancestor_id IS NOT NULL LOOP
+    UPDATE comments
+    SET reply_count = reply_count + CASE WHEN id = NEW.parent_id THEN 1 ELSE 0 END,
+        descendant_count = descendant_count + 1,
+        last_reply_at = GREATEST(last_reply_at, NEW.created_at)
+    WHERE id = ancestor_id
+    RETURNING parent_id INTO ancestor_id;
+  END LOOP;
+  RETURN NULL;
+END;
+$func$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE FUNCTION comments_reply_stats_delete() RETURNS trigger AS $func$
+DECLARE
+  ancestor_id INT := OLD.parent_id;
+BEGIN
+  -- replies removed by the cascade find their parent already gone, so only
+  -- the root of a deleted subtree updates the comments above it
+  WHILE ancestor_id IS NOT NULL LOOP
+    UPDATE comments c
+    SET reply_count = reply_count - CASE WHEN id = OLD.parent_id THEN 1 ELSE 0 END,
+        descendant_count = descendant_count - 1 - OLD.descendant_count,
+        last_reply_at = (
+          SELECT MAX(GREATEST(ch.created_at, ch.last_reply_at))
+          FROM comments ch
+          WHERE ch.parent_id = c.id
+        )
+    WHERE id = ancestor_id
+    RETURNING parent_id INTO ancestor_id;
+  END LOOP;
+  RETURN NULL;
+END;
+$func$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER comments_reply_stats_insert AFTER INSERT
+ON comments FOR EACH ROW EXECUTE FUNCTION comments_reply_stats_insert();
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER comments_reply_stats_delete AFTER DELETE
+ON comments FOR EACH ROW EXECUTE FUNCTION comments_reply_stats_delete();
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS comments_reply_stats_delete ON comments;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS comments_reply_stats_insert ON comments;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP FUNCTION IF EXISTS comments_reply_stats_delete();
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP FUNCTION IF EXISTS comments_reply_stats_insert();
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE comments
+    DROP COLUMN IF EXISTS last_reply_at,
+    DROP COLUMN IF EXISTS descendant_count,
+    DROP COLUMN IF EXISTS reply_count;
+-- +goose StatementEnd
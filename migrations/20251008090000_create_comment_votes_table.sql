@@ -0,0 +1,105 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE comments
+    ADD COLUMN upvotes INT NOT NULL DEFAULT 0,
+    ADD COLUMN downvotes INT NOT NULL DEFAULT 0,
+    ADD COLUMN score INT NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS comment_votes(
+    comment_id INT NOT NULL REFERENCES comments(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL,
+    value SMALLINT NOT NULL CHECK (value IN (-1, 1)),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (comment_id, user_id)
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX idx_comment_votes_user_id ON comment_votes(user_id, comment_id);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS comment_votes_trash(
+    comment_id INT NOT NULL REFERENCES comments_trash(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL,
+    value SMALLINT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (comment_id, user_id)
+);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE FUNCTION comment_votes_tally() RETURNS trigger AS $func$
+DECLARE
+  voted_comment_id INT;
+  up_delta INT := 0;
+  down_delta INT := 0;
+BEGIN
+  IF TG_OP IN ('UPDATE', 'DELETE') THEN
+    voted_comment_id := OLD.comment_id;
+    IF OLD.value = 1 THEN up_delta := up_delta - 1; ELSE down_delta := down_delta - 1; END IF;
+  END IF;
+  IF TG_OP IN ('INSERT', 'UPDATE') THEN
+    voted_comment_id := NEW.comment_id;
+    IF NEW.value = 1 THEN up_delta := up_delta + 1; ELSE down_delta := down_delta + 1; END IF;
+  END IF;
+
+  UPDATE comments
+  SET upvotes = upvotes + up_delta,
+      downvotes = downvotes + down_delta,
+      score = score + up_delta - down_delta
+  WHERE id = voted_comment_id;
+  RETURN NULL;
+END;
+$func$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER comment_votes_tally AFTER INSERT OR UPDATE OF value OR DELETE
+ON comment_votes FOR EACH ROW EXECUTE FUNCTION comment_votes_tally();
+-- +goose StatementEnd
+
+-- comments_wilson_lower_bound ranks comments by the lower bound of the
+-- 95% Wilson score interval of their share of upvotes
+-- +goose StatementBegin
+CREATE FUNCTION comments_wilson_lower_bound(upvotes INT, downvotes INT) RETURNS DOUBLE PRECISION AS $func$
+  SELECT CASE WHEN upvotes + downvotes = 0 THEN 0 ELSE
+    (upvotes::float8 / (upvotes + downvotes) + 1.9208 / (upvotes + downvotes)
+      - 1.96 * sqrt(upvotes::float8 * downvotes / (upvotes + downvotes) + 0.9604) / (upvotes + downvotes))
+    / (1 + 3.8416 / (upvotes + downvotes))
+  END
+$func$ LANGUAGE sql IMMUTABLE;
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+DROP FUNCTION IF EXISTS comments_wilson_lower_bound(INT, INT);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS comment_votes_tally ON comment_votes;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP FUNCTION IF EXISTS comment_votes_tally();
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE IF EXISTS comment_votes_trash;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE IF EXISTS comment_votes;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE comments
+    DROP COLUMN IF EXISTS score,
+    DROP COLUMN IF EXISTS downvotes,
+    DROP COLUMN IF EXISTS upvotes;
+-- +goose StatementEnd
@@ -0,0 +1,51 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE FUNCTION comments_reply_stats_move() RETURNS trigger AS $func$
+DECLARE
+  ancestor_id INT := OLD.parent_id;
+BEGIN
+  -- take the moved subtree out of the stats of its old ancestors
+  WHILE ancestor_id IS NOT NULL LOOP
+    UPDATE comments c
+    SET reply_count = reply_count - CASE WHEN id = OLD.parent_id THEN 1 ELSE 0 END,
+        descendant_count = descendant_count - 1 - NEW.descendant_count,
+        last_reply_at = (
+          SELECT MAX(GREATEST(ch.created_at, ch.last_reply_at))
+          FROM comments ch
+          WHERE ch.parent_id = c.id
+        )
+    WHERE id = ancestor_id
+    RETURNING parent_id INTO ancestor_id;
+  END LOOP;
+
+  -- and add it to the stats of the new ones
+  ancestor_id := NEW.parent_id;
+  WHILE ancestor_id IS NOT NULL LOOP
+    UPDATE comments
+    SET reply_count = reply_count + CASE WHEN id = NEW.parent_id THEN 1 ELSE 0 END,
+        descendant_count = descendant_count + 1 + NEW.descendant_count,
+        last_reply_at = GREATEST(last_reply_at, NEW.created_at, NEW.last_reply_at)
+    WHERE id = ancestor_id
+    RETURNING parent_id INTO ancestor_id;
+  END LOOP;
+  RETURN NULL;
+END;
+$func$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER comments_reply_stats_move AFTER UPDATE OF parent_id
+ON comments FOR EACH ROW
+WHEN (OLD.parent_id IS DISTINCT FROM NEW.parent_id)
+EXECUTE FUNCTION comments_reply_stats_move();
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS comments_reply_stats_move ON comments;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP FUNCTION IF EXISTS comments_reply_stats_move();
+-- +goose StatementEnd
@@ -0,0 +1,103 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE EXTENSION IF NOT EXISTS ltree;
+-- +goose StatementEnd
+
+-- path lists the ids from the root of the thread down to the comment
+-- itself, e.g. 1.5.9, and depth is the number of its ancestors
+-- +goose StatementBegin
+ALTER TABLE comments ADD COLUMN path ltree;
+-- +goose StatementEnd
+
+-- comments_rebuild_paths recomputes the paths of comment root_id and all of
+-- its replies from the parent_id links
+-- +goose StatementBegin
+CREATE FUNCTION comments_rebuild_paths(root_id INT) RETURNS void AS $func$
+  WITH RECURSIVE paths AS (
+    SELECT c.id, COALESCE(p.path, ''::ltree) || c.id::text AS path
+    FROM comments c
+    LEFT JOIN comments p ON p.id = c.parent_id
+    WHERE c.id = root_id
+
+    UNION ALL
+
+    SELECT c.id, ps.path || c.id::text
+    FROM comments c
+    INNER JOIN paths ps ON c.parent_id = ps.id
+  )
+  UPDATE comments c
+  SET path = ps.path
+  FROM paths ps
+  WHERE ps.id = c.id AND c.path IS DISTINCT FROM ps.path;
+$func$ LANGUAGE sql;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+SELECT comments_rebuild_paths(id) FROM comments WHERE parent_id IS NULL;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE comments
+    ALTER COLUMN path SET NOT NULL,
+    ADD COLUMN depth INT GENERATED ALWAYS AS (nlevel(path) - 1) STORED;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX idx_comments_path ON comments USING GIST (path);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE FUNCTION comments_set_path() RETURNS trigger AS $func$
+BEGIN
+  NEW.path := COALESCE((SELECT path FROM comments WHERE id = NEW.parent_id), ''::ltree) || NEW.id::text;
+
+  -- a moved comment takes its replies along
+  IF TG_OP = 'UPDATE' THEN
+    UPDATE comments
+    SET path = NEW.path || subpath(path, nlevel(OLD.path))
+    WHERE path <@ OLD.path AND id <> NEW.id;
+  END IF;
+  RETURN NEW;
+END;
+$func$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER comments_set_path_insert BEFORE INSERT
+ON comments FOR EACH ROW EXECUTE FUNCTION comments_set_path();
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER comments_set_path_move BEFORE UPDATE OF parent_id
+ON comments FOR EACH ROW
+WHEN (OLD.parent_id IS DISTINCT FROM NEW.parent_id)
+EXECUTE FUNCTION comments_set_path();
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS comments_set_path_move ON comments;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS comments_set_path_insert ON comments;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP FUNCTION IF EXISTS comments_set_path();
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP FUNCTION IF EXISTS comments_rebuild_paths(INT);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_comments_path;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE comments
+    DROP COLUMN IF EXISTS depth,
+    DROP COLUMN IF EXISTS path;
+-- +goose StatementEnd
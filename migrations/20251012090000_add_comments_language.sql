@@ -0,0 +1,62 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE comments
+    ADD COLUMN language TEXT NOT NULL DEFAULT 'ru',
+    ADD COLUMN search_config REGCONFIG NOT NULL DEFAULT 'russian';
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE comments_trash
+    ADD COLUMN language TEXT NOT NULL DEFAULT 'ru',
+    ADD COLUMN search_config REGCONFIG NOT NULL DEFAULT 'russian';
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION comments_search_vector_update() RETURNS trigger AS $func$
+BEGIN
+  NEW.search_vector := to_tsvector(NEW.search_config, coalesce(NEW.text, ''));
+  RETURN NEW;
+END;
+$func$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS tsvectorupdate ON comments;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER tsvectorupdate BEFORE INSERT OR UPDATE OF text, search_config
+ON comments FOR EACH ROW EXECUTE FUNCTION comments_search_vector_update();
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS tsvectorupdate ON comments;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER tsvectorupdate BEFORE INSERT OR UPDATE OF text
+ON comments FOR EACH ROW EXECUTE FUNCTION comments_search_vector_update();
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION comments_search_vector_update() RETURNS trigger AS $func$
+BEGIN
+  NEW.search_vector := to_tsvector('russian', coalesce(NEW.text, ''));
+  RETURN NEW;
+END;
+$func$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE comments_trash
+    DROP COLUMN IF EXISTS search_config,
+    DROP COLUMN IF EXISTS language;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+ALTER TABLE comments
+    DROP COLUMN IF EXISTS search_config,
+    DROP COLUMN IF EXISTS language;
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- +goose Up
+-- without upvotes the lower bound is exactly zero, but the formula leaves a
+-- rounding error that ranks downvoted comments above comments without votes
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION comments_wilson_lower_bound(upvotes INT, downvotes INT) RETURNS DOUBLE PRECISION AS $func$
+  SELECT CASE WHEN upvotes = 0 THEN 0 ELSE
+    (upvotes::float8 / (upvotes + downvotes) + 1.9208 / (upvotes + downvotes)
+      - 1.96 * sqrt(upvotes::float8 * downvotes / (upvotes + downvotes) + 0.9604) / (upvotes + downvotes))
+    / (1 + 3.8416 / (upvotes + downvotes))
+  END
+$func$ LANGUAGE sql IMMUTABLE;
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION comments_wilson_lower_bound(upvotes INT, downvotes INT) RETURNS DOUBLE PRECISION AS $func$
+  SELECT CASE WHEN upvotes + downvotes = 0 THEN 0 ELSE
+    (upvotes::float8 / (upvotes + downvotes) + 1.9208 / (upvotes + downvotes)
+      - 1.96 * sqrt(upvotes::float8 * downvotes / (upvotes + downvotes) + 0.9604) / (upvotes + downvotes))
+    / (1 + 3.8416 / (upvotes + downvotes))
+  END
+$func$ LANGUAGE sql IMMUTABLE;
+-- +goose StatementEnd
@@ -0,0 +1,43 @@
+-- +goose Up
+-- a new reply takes the advisory lock of moves (moveLockKey in the
+-- repository) in shared mode before it reads the path of its parent, so it
+-- waits for a move in progress and a move waits for it; otherwise a reply
+-- added under a moving subtree keeps the path from before the move. Shared
+-- locks do not block each other, so replies are not serialised.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION comments_set_path() RETURNS trigger AS $func$
+BEGIN
+  IF TG_OP = 'INSERT' THEN
+    PERFORM pg_advisory_xact_lock_shared(7305511);
+  END IF;
+
+  NEW.path := COALESCE((SELECT path FROM comments WHERE id = NEW.parent_id), ''::ltree) || NEW.id::text;
+
+  -- a moved comment takes its replies along
+  IF TG_OP = 'UPDATE' THEN
+    UPDATE comments
+    SET path = NEW.path || subpath(path, nlevel(OLD.path))
+    WHERE path <@ OLD.path AND id <> NEW.id;
+  END IF;
+  RETURN NEW;
+END;
+$func$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION comments_set_path() RETURNS trigger AS $func$
+BEGIN
+  NEW.path := COALESCE((SELECT path FROM comments WHERE id = NEW.parent_id), ''::ltree) || NEW.id::text;
+
+  -- a moved comment takes its replies along
+  IF TG_OP = 'UPDATE' THEN
+    UPDATE comments
+    SET path = NEW.path || subpath(path, nlevel(OLD.path))
+    WHERE path <@ OLD.path AND id <> NEW.id;
+  END IF;
+  RETURN NEW;
+END;
+$func$ LANGUAGE plpgsql;
+-- +goose StatementEnd
@@ -1,17 +1,27 @@
 const API_BASE = ''; 
+const SUGGEST_DELAY_MS = 250;
+
+let suggestTimer;
 
 document.addEventListener('DOMContentLoaded', () => {
     document.getElementById('load-comments-btn').addEventListener('click', loadComments);
     document.getElementById('search-btn').addEventListener('click', searchComments);
+    document.getElementById('search-input').addEventListener('input', scheduleSuggestions);
     document.getElementById('clear-search-btn').addEventListener('click', loadComments);
     document.getElementById('create-comment-btn').addEventListener('click', createComment);
 });
 
+// The user is identified by the authenticating gateway in front of the
+// service, so a 401 means the user has not signed in there.
+function requestError(response, message) {
+    return new Error(response.status === 401 ? 'please sign in first' : message);
+}
+
 async function loadComments() {
     try {
         const response = await fetch(`${API_BASE}/comments/all`);
         if (!response.ok) throw new Error('Failed to load comments');
-        const comments = await response.json();
+        const { items: comments } = await response.json();
         const container = document.getElementById('comments-container');
         container.innerHTML = ''; // Clear container before displaying
         displayComments(comments, container, 0);
@@ -32,11 +42,16 @@ function displayComments(comments, container, level) {
         commentDiv.style.marginLeft = `${level * 20}px`;
 
         commentDiv.innerHTML = `
-            <div class="text">${escapeHtml(comment.text)}</div>
-            <div class="meta">ID: ${comment.id} | Created: ${new Date(comment.created_at).toLocaleString()}</div>
+            ${comment.ancestors ? `<div class="context">In reply to: ${comment.ancestors.map(ancestor => `#${ancestor.id} ${escapeHtml(ancestor.author ? ancestor.author.display_name : 'anonymous')}`).join(' › ')}</div>` : ''}
+            <div class="text">${comment.snippet ? highlightSnippet(comment.snippet) : escapeHtml(comment.text)}</div>
+            <div class="meta">ID: ${comment.id} | Author: ${escapeHtml(comment.author ? comment.author.display_name : 'anonymous')} | Created: ${new Date(comment.created_at).toLocaleString()}${comment.edited_at ? ' (edited)' : ''}${comment.reply_count ? ` | Replies: ${comment.reply_count}` : ''}</div>
             <div class="actions">
+                ${comment.deleted ? '' : `<button class="vote-btn${comment.my_vote === 1 ? ' voted' : ''}" data-id="${comment.id}" data-vote="${comment.my_vote === 1 ? 'clear' : 'up'}">▲</button>
+                <span class="score">${comment.score}</span>
+                <button class="vote-btn${comment.my_vote === -1 ? ' voted' : ''}" data-id="${comment.id}" data-vote="${comment.my_vote === -1 ? 'clear' : 'down'}">▼</button>
+                ${reactionButtons(comment)}`}
                 <button class="reply-btn" data-id="${comment.id}">Reply</button>
-                <button class="delete-btn" data-id="${comment.id}">Delete</button>
+                ${comment.deleted ? '' : `<button class="delete-btn" data-id="${comment.id}">Delete</button>`}
             </div>
             <div class="reply-form" id="reply-form-${comment.id}">
                 <textarea placeholder="Write your reply..."></textarea>
@@ -49,7 +64,11 @@ function displayComments(comments, container, level) {
 
         // Add event listeners
         commentDiv.querySelector('.reply-btn').addEventListener('click', toggleReplyForm);
-        commentDiv.querySelector('.delete-btn').addEventListener('click', deleteComment);
+        if (!comment.deleted) {
+            commentDiv.querySelector('.delete-btn').addEventListener('click', deleteComment);
+            commentDiv.querySelectorAll('.vote-btn').forEach(btn => btn.addEventListener('click', voteComment));
+            commentDiv.querySelectorAll('.reaction-btn').forEach(btn => btn.addEventListener('click', toggleReaction));
+        }
         commentDiv.querySelector('.submit-reply-btn').addEventListener('click', submitReply);
         commentDiv.querySelector('.cancel-reply-btn').addEventListener('click', cancelReply);
 
@@ -78,7 +97,7 @@ async function submitReply(event) {
             headers: { 'Content-Type': 'application/json' },
             body: JSON.stringify({ parent_id: parseInt(parentId), text })
         });
-        if (!response.ok) throw new Error('Failed to create reply');
+        if (!response.ok) throw requestError(response, 'Failed to create reply');
         textarea.value = '';
         document.getElementById(`reply-form-${parentId}`).style.display = 'none';
         loadComments();
@@ -98,13 +117,57 @@ async function deleteComment(event) {
     
     try {
         const response = await fetch(`${API_BASE}/comments/${id}`, { method: 'DELETE' });
-        if (!response.ok) throw new Error('Failed to delete comment');
+        if (!response.ok) throw requestError(response, 'Failed to delete comment');
         loadComments(); 
     } catch (error) {
         alert('Error deleting comment: ' + error.message);
     }
 }
 
+async function voteComment(event) {
+    const { id, vote } = event.target.dataset;
+
+    try {
+        const response = await fetch(`${API_BASE}/comments/${id}/vote`, {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ vote })
+        });
+        if (!response.ok) throw requestError(response, 'Failed to vote');
+        loadComments();
+    } catch (error) {
+        alert('Error voting: ' + error.message);
+    }
+}
+
+const QUICK_REACTIONS = ['👍', '❤️', '😂'];
+
+function reactionButtons(comment) {
+    const reactions = [...(comment.reactions || [])];
+    QUICK_REACTIONS.forEach(emoji => {
+        if (!reactions.some(r => r.emoji === emoji)) reactions.push({ emoji, count: 0, reacted: false });
+    });
+    return reactions.map(r => `<button class="reaction-btn${r.reacted ? ' reacted' : ''}" data-id="${comment.id}" data-emoji="${escapeHtml(r.emoji)}" data-reacted="${r.reacted}">${escapeHtml(r.emoji)}${r.count ? ` ${r.count}` : ''}</button>`).join('');
+}
+
+async function toggleReaction(event) {
+    const { id, emoji, reacted } = event.currentTarget.dataset;
+
+    try {
+        const response = reacted === 'true'
+            ? await fetch(`${API_BASE}/comments/${id}/reactions/${encodeURIComponent(emoji)}`, { method: 'DELETE' })
+            : await fetch(`${API_BASE}/comments/${id}/reactions`, {
+                method: 'POST',
+                headers: { 'Content-Type': 'application/json' },
+                body: JSON.stringify({ emoji })
+            });
+        if (!response.ok) throw requestError(response, 'Failed to react');
+        loadComments();
+    } catch (error) {
+        alert('Error reacting: ' + error.message);
+    }
+}
+
 async function createComment() {
     const text = document.getElementById('new-comment-text').value.trim();
     const parentIdStr = document.getElementById('new-comment-parent-id').value.trim();
@@ -123,7 +186,7 @@ async function createComment() {
             headers: { 'Content-Type': 'application/json' },
             body: JSON.stringify(body)
         });
-        if (!response.ok) throw new Error('Failed to create comment');
+        if (!response.ok) throw requestError(response, 'Failed to create comment');
         document.getElementById('new-comment-text').value = '';
         document.getElementById('new-comment-parent-id').value = '';
         loadComments();
@@ -140,10 +203,10 @@ async function searchComments() {
         const response = await fetch(`${API_BASE}/comments/search`, {
             method: 'POST',
             headers: { 'Content-Type': 'application/json' },
-            body: JSON.stringify({ text: query })
+            body: JSON.stringify({ text: query, highlight: { start_sel: '<mark>', stop_sel: '</mark>', max_fragments: 2 } })
         });
         if (!response.ok) throw new Error('Failed to search comments');
-        const comments = await response.json();
+        const { items: comments } = await response.json();
         const container = document.getElementById('comments-container');
         container.innerHTML = ''; // Clear container before displaying
         displayComments(comments, container, 0);
@@ -152,6 +215,48 @@ async function searchComments() {
     }
 }
 
+// scheduleSuggestions asks for suggestions once the user pauses typing.
+function scheduleSuggestions() {
+    clearTimeout(suggestTimer);
+    suggestTimer = setTimeout(loadSuggestions, SUGGEST_DELAY_MS);
+}
+
+async function loadSuggestions() {
+    const query = document.getElementById('search-input').value.trim();
+    const datalist = document.getElementById('search-suggestions');
+    if (query.length < 2) {
+        datalist.innerHTML = '';
+        return;
+    }
+
+    try {
+        const response = await fetch(`${API_BASE}/comments/suggest?q=${encodeURIComponent(query)}`);
+        if (!response.ok) return;
+        const { comments, terms } = await response.json();
+
+        datalist.innerHTML = '';
+        const addOption = value => {
+            const option = document.createElement('option');
+            option.value = value;
+            datalist.appendChild(option);
+        };
+
+        // terms complete the last word of the query
+        const words = query.split(/\s+/);
+        terms.forEach(term => addOption([...words.slice(0, -1), term].join(' ')));
+        comments.forEach(comment => addOption(comment.excerpt.replace(/<\/?b>/g, '')));
+    } catch (error) {
+        datalist.innerHTML = '';
+    }
+}
+
+// highlightSnippet escapes a search snippet but keeps its <mark> tags.
+function highlightSnippet(snippet) {
+    return escapeHtml(snippet)
+        .replaceAll('&lt;mark&gt;', '<mark>')
+        .replaceAll('&lt;/mark&gt;', '</mark>');
+}
+
 function escapeHtml(text) {
     const div = document.createElement('div');
     div.textContent = text;